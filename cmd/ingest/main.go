@@ -49,7 +49,7 @@ var rootCmd = &cobra.Command{
 }
 
 func init() {
-	rootCmd.Flags().StringVar(&env, "env", "", "Specify the path of an env file to load")
+	rootCmd.PersistentFlags().StringVar(&env, "env", "", "Specify the path of an env file to load")
 }
 
 var env string
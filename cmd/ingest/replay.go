@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/metdatasystem/mds-awips/internal/ingest"
+	"github.com/spf13/cobra"
+)
+
+var replayCmd = &cobra.Command{
+	Use:   "replay [paths...]",
+	Short: "Publish archived products from files or directories to be parsed",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+		defer stop()
+
+		if env != "" {
+			err := godotenv.Load(env)
+			if err != nil {
+				slog.Error("failed loading env", "error", err)
+				return
+			}
+		}
+
+		config, err := newReplayConfig()
+		if err != nil {
+			slog.Error("invalid replay options", "error", err)
+			return
+		}
+
+		published, err := ingest.Replay(ctx, args, config)
+		if err != nil {
+			slog.Error("failed to replay products", "error", err, "published", published)
+			return
+		}
+
+		slog.Info("replay finished", "published", published)
+	},
+}
+
+func init() {
+	replayCmd.Flags().Float64Var(&rate, "rate", 0, "How many products to publish per second, 0 for no limit")
+	replayCmd.Flags().BoolVar(&issueTime, "issue-time", false, "Use the product issue time as the received time")
+	replayCmd.Flags().StringVar(&from, "from", "", "Only products issued at or after this time (RFC 3339 or YYYY-MM-DD)")
+	replayCmd.Flags().StringVar(&until, "until", "", "Only products issued before this time (RFC 3339 or YYYY-MM-DD)")
+	rootCmd.AddCommand(replayCmd)
+}
+
+var rate float64
+var issueTime bool
+var from string
+var until string
+
+func newReplayConfig() (ingest.ReplayConfig, error) {
+	config := ingest.ReplayConfig{
+		Rate:      rate,
+		IssueTime: issueTime,
+	}
+
+	var err error
+	config.Since, err = parseTime(from)
+	if err != nil {
+		return config, fmt.Errorf("invalid --from: %w", err)
+	}
+	config.Until, err = parseTime(until)
+	if err != nil {
+		return config, fmt.Errorf("invalid --until: %w", err)
+	}
+	if !config.Since.IsZero() && !config.Until.IsZero() && !config.Since.Before(config.Until) {
+		return config, fmt.Errorf("--from must be before --until")
+	}
+
+	return config, nil
+}
+
+// Parse a time given as RFC 3339 or a date in UTC. An empty string is the zero time.
+func parseTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", value)
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewReplayConfig(t *testing.T) {
+	tests := []struct {
+		name      string
+		from      string
+		until     string
+		since     time.Time
+		before    time.Time
+		expectErr bool
+	}{
+		{name: "no filter"},
+		{
+			name:   "dates",
+			from:   "2025-06-26",
+			until:  "2025-06-27",
+			since:  time.Date(2025, 6, 26, 0, 0, 0, 0, time.UTC),
+			before: time.Date(2025, 6, 27, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:  "time",
+			from:  "2025-06-26T19:36:00-04:00",
+			since: time.Date(2025, 6, 26, 23, 36, 0, 0, time.UTC),
+		},
+		{name: "bad from", from: "26/06/2025", expectErr: true},
+		{name: "bad until", until: "tomorrow", expectErr: true},
+		{name: "from after until", from: "2025-06-27", until: "2025-06-26", expectErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			from, until, rate, issueTime = test.from, test.until, 2, true
+			defer func() { from, until, rate, issueTime = "", "", 0, false }()
+
+			config, err := newReplayConfig()
+			if test.expectErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %+v", config)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !config.Since.Equal(test.since) || !config.Until.Equal(test.before) {
+				t.Errorf("expected %v to %v, got %v to %v", test.since, test.before, config.Since, config.Until)
+			}
+			if config.Rate != 2 || !config.IssueTime {
+				t.Errorf("expected the rate and issue time flags to be kept, got %+v", config)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/metdatasystem/mds-awips/internal/parse/infrastructure/db"
+	"github.com/spf13/cobra"
+)
+
+var failuresCmd = &cobra.Command{
+	Use:   "failures",
+	Short: "List products that failed to be parsed or stored",
+	Run: func(cmd *cobra.Command, args []string) {
+		if env != "" {
+			err := godotenv.Load(env)
+			if err != nil {
+				slog.Error("failed loading env", "error", err)
+				return
+			}
+		}
+
+		pool, err := db.New()
+		if err != nil {
+			slog.Error("failed to connect to the database", "error", err)
+			return
+		}
+		defer pool.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		logs, err := db.NewLogRepository(pool).GetFailures(ctx, time.Now().UTC().Add(-since))
+		if err != nil {
+			slog.Error("failed to get failures", "error", err)
+			return
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "TIME\tAWIPS\tWMO\tPRODUCT\tMESSAGE")
+		for _, log := range logs {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", log.Time.UTC().Format(time.RFC3339), log.AWIPS, log.WMO, log.Product, log.Message)
+		}
+		w.Flush()
+	},
+}
+
+func init() {
+	failuresCmd.Flags().DurationVar(&since, "since", 24*time.Hour, "How far back to look for failures")
+	rootCmd.AddCommand(failuresCmd)
+}
+
+var since time.Duration
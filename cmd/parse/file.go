@@ -0,0 +1,67 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/metdatasystem/mds-awips/internal/parse/handler"
+	"github.com/spf13/cobra"
+)
+
+var fileCmd = &cobra.Command{
+	Use:   "file [path]",
+	Short: "Print what the parser makes of a product as JSON, reading from stdin if no path is given",
+	Long: `Print what the parser makes of a product as JSON, reading from stdin if no path is given.
+The product is parsed as it would be from the queue. Segments are printed without anything that failed to parse and
+those errors are printed to stderr.`,
+	Args:         cobra.MaximumNArgs(1),
+	SilenceUsage: true, // Failing to parse the product is not a usage error
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var data []byte
+		var err error
+		if len(args) == 0 || args[0] == "-" {
+			data, err = io.ReadAll(cmd.InOrStdin())
+		} else {
+			data, err = os.ReadFile(args[0])
+		}
+		if err != nil {
+			return err
+		}
+
+		// Nothing is logged, the errors are printed after the product instead
+		h := handler.New(cmd.Context(), nil, nil, int(slog.LevelError)+1, string(data), time.Now().UTC(), handler.Metadata{})
+		product, errs, err := h.Parse()
+		if err != nil {
+			return fmt.Errorf("failed to parse product: %w", err)
+		}
+
+		var output any = product
+		if geoJSON {
+			output = product.GeoJSON()
+		}
+
+		encoder := json.NewEncoder(cmd.OutOrStdout())
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(output)
+		if err != nil {
+			return err
+		}
+
+		for _, e := range errs {
+			fmt.Fprintln(cmd.ErrOrStderr(), e)
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	fileCmd.Flags().BoolVar(&geoJSON, "geojson", false, "Print the polygons and storm locations as GeoJSON")
+	rootCmd.AddCommand(fileCmd)
+}
+
+var geoJSON bool
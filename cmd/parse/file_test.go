@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/metdatasystem/mds-awips/pkg/awips"
+)
+
+const testSVS = `WWUS52 KRAH 262350
+SVSRAH
+
+Severe Weather Statement
+National Weather Service Raleigh NC
+750 PM EDT Thu Jun 26 2025
+
+NCC063-270015-
+/O.CON.KRAH.SV.W.0175.000000T0000Z-250627T0015Z/
+
+LAT...LON 3581 7875 3573 7903 3589 7912 3600 7888
+TIME...MOT...LOC 2350Z 254DEG 21KT 3583 7896
+
+$$
+
+NCC135-273015-
+/O.CON.KRAH.SV.W.0176.000000T0000Z-250627T0015Z/
+
+LAT...LON 3581 7875 3573
+
+$$
+`
+
+// Run the file command, returning what it printed to stdout and stderr
+func runFile(t *testing.T, stdin string, args ...string) (string, string, error) {
+	t.Helper()
+
+	var stdout, stderr bytes.Buffer
+	rootCmd.SetIn(strings.NewReader(stdin))
+	rootCmd.SetOut(&stdout)
+	rootCmd.SetErr(&stderr)
+	rootCmd.SetArgs(append([]string{"file"}, args...))
+	defer func() {
+		rootCmd.SetIn(nil)
+		rootCmd.SetOut(nil)
+		rootCmd.SetErr(nil)
+		rootCmd.SetArgs(nil)
+		geoJSON = false
+	}()
+
+	err := rootCmd.Execute()
+	return stdout.String(), stderr.String(), err
+}
+
+func TestFileCommand(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "svs.txt")
+	err := os.WriteFile(path, []byte(testSVS), 0o644)
+	if err != nil {
+		t.Fatalf("failed to write product: %v", err)
+	}
+
+	stdout, stderr, err := runFile(t, "", path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	product := awips.TextProduct{}
+	err = json.Unmarshal([]byte(stdout), &product)
+	if err != nil {
+		t.Fatalf("expected the product as JSON, got %q: %v", stdout, err)
+	}
+	if product.AWIPS.Original != "SVSRAH" {
+		t.Errorf("expected SVSRAH, got %s", product.AWIPS.Original)
+	}
+
+	// The bad segment is still printed, without its UGC and polygon
+	if len(product.Segments) != 2 {
+		t.Fatalf("expected 2 segments, got %d", len(product.Segments))
+	}
+	if product.Segments[0].LatLon == nil || product.Segments[0].UGC == nil {
+		t.Errorf("expected the first segment to have a polygon and UGC")
+	}
+	if product.Segments[1].LatLon != nil || product.Segments[1].UGC != nil || len(product.Segments[1].VTEC) != 1 {
+		t.Errorf("expected the second segment to have its VTEC without a polygon or UGC")
+	}
+
+	lines := strings.Split(strings.TrimSpace(stderr), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "ugc:") || !strings.HasPrefix(lines[1], "latlon:") {
+		t.Errorf("expected the UGC and LAT...LON errors on stderr, got %q", stderr)
+	}
+}
+
+func TestFileCommandGeoJSON(t *testing.T) {
+	stdout, _, err := runFile(t, testSVS, "--geojson")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	collection := struct {
+		Type     string            `json:"type"`
+		Features []json.RawMessage `json:"features"`
+	}{}
+	err = json.Unmarshal([]byte(stdout), &collection)
+	if err != nil {
+		t.Fatalf("expected GeoJSON, got %q: %v", stdout, err)
+	}
+	if collection.Type != "FeatureCollection" || len(collection.Features) == 0 {
+		t.Errorf("expected a feature collection with features, got %s", stdout)
+	}
+}
+
+func TestFileCommandNotAProduct(t *testing.T) {
+	stdout, _, err := runFile(t, "WWUS52 KRAH 262350\n\n", "-")
+	if err == nil {
+		t.Fatal("expected an error for a product without an AWIPS header")
+	}
+	if stdout != "" {
+		t.Errorf("expected nothing on stdout, got %q", stdout)
+	}
+
+	_, _, err = runFile(t, "", filepath.Join(t.TempDir(), "missing.txt"))
+	if err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
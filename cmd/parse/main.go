@@ -6,6 +6,7 @@ import (
 	"log/slog"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/metdatasystem/mds-awips/internal/parse"
@@ -29,7 +30,11 @@ var rootCmd = &cobra.Command{
 		}
 
 		config := parse.Config{
-			MinLog: minlog,
+			MinLog:       minlog,
+			MaxRetries:   retries,
+			RetryBackoff: backoff,
+			Prefetch:     prefetch,
+			Workers:      workers,
 		}
 
 		server, err := parse.New(config)
@@ -38,7 +43,8 @@ var rootCmd = &cobra.Command{
 			return
 		}
 
-		go server.Start()
+		// Returns once the consumer is running, so it is always part of the shutdown
+		server.Start()
 
 		<-ctx.Done()
 		slog.Info("shutting down")
@@ -48,12 +54,20 @@ var rootCmd = &cobra.Command{
 }
 
 func init() {
-	rootCmd.Flags().StringVar(&env, "env", "", "Specify the path of an env file to load")
+	rootCmd.PersistentFlags().StringVar(&env, "env", "", "Specify the path of an env file to load")
 	rootCmd.Flags().IntVar(&minlog, "minlog", 0, "The minimum logging level to use")
+	rootCmd.Flags().IntVar(&retries, "retries", 5, "How many times to retry a product that failed to be stored")
+	rootCmd.Flags().DurationVar(&backoff, "backoff", time.Second, "The initial wait between retries, doubled after each attempt")
+	rootCmd.Flags().IntVar(&prefetch, "prefetch", 100, "How many unacknowledged messages to hold at once, at least 1")
+	rootCmd.Flags().IntVar(&workers, "workers", 8, "How many products to handle at once")
 }
 
 var env string
 var minlog int
+var retries int
+var backoff time.Duration
+var prefetch int
+var workers int
 
 func main() {
 	err := rootCmd.Execute()
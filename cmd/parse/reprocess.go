@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/metdatasystem/mds-awips/internal/parse"
+	"github.com/metdatasystem/mds-awips/internal/parse/infrastructure/db"
+	"github.com/spf13/cobra"
+)
+
+var reprocessCmd = &cobra.Command{
+	Use:   "reprocess",
+	Short: "Pass stored products through the handlers again to rebuild what is derived from them",
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+		defer stop()
+
+		if env != "" {
+			err := godotenv.Load(env)
+			if err != nil {
+				slog.Error("failed loading env", "error", err)
+				return
+			}
+		}
+
+		filter := db.ProductFilter{
+			Office:    strings.ToUpper(office),
+			AWIPS:     strings.ToUpper(awipsID),
+			ProductID: productID,
+		}
+
+		var err error
+		filter.Since, err = parseTime(from)
+		if err != nil {
+			slog.Error("invalid --from", "error", err)
+			return
+		}
+		filter.Until, err = parseTime(until)
+		if err != nil {
+			slog.Error("invalid --until", "error", err)
+			return
+		}
+
+		config := parse.ReprocessConfig{
+			MinLog:   minlog,
+			Filter:   filter,
+			Truncate: truncate,
+		}
+
+		processed, failures, err := parse.Reprocess(ctx, config)
+		if err != nil {
+			slog.Error("failed to reprocess products", "error", err, "processed", processed)
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "PRODUCT\tERROR")
+		for _, failure := range failures {
+			for _, e := range failure.Errors {
+				fmt.Fprintf(w, "%s\t%s\n", failure.ProductID, e)
+			}
+		}
+		w.Flush()
+
+		slog.Info("reprocessing finished", "processed", processed, "failed", len(failures))
+	},
+}
+
+func init() {
+	reprocessCmd.Flags().StringVar(&from, "from", "", "Only products issued at or after this time (RFC 3339 or YYYY-MM-DD)")
+	reprocessCmd.Flags().StringVar(&until, "until", "", "Only products issued before this time (RFC 3339 or YYYY-MM-DD)")
+	reprocessCmd.Flags().StringVar(&office, "office", "", "Only products from this office, e.g. OKX")
+	reprocessCmd.Flags().StringVar(&awipsID, "awips", "", "Only products with this AWIPS identifier, e.g. TOROKX")
+	reprocessCmd.Flags().StringVar(&productID, "product", "", "Only the product with this product ID")
+	reprocessCmd.Flags().BoolVar(&truncate, "truncate", false, "Remove all VTEC events, updates, UGCs, tropical threats and MCDs before reprocessing so VTEC events are rebuilt too. Can not be used with a filter")
+	reprocessCmd.Flags().IntVar(&minlog, "minlog", 0, "The minimum logging level to use")
+	rootCmd.AddCommand(reprocessCmd)
+}
+
+var from string
+var until string
+var office string
+var awipsID string
+var productID string
+var truncate bool
+
+// Parse a time given as RFC 3339 or a date in UTC. An empty string is the zero time.
+func parseTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", value)
+}
@@ -0,0 +1,77 @@
+package ingest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+)
+
+// Drops messages that have already been seen within a time window, such as the same product received from two NWWS-OI servers.
+// Messages are matched by their NWWS-OI id or a hash of their text.
+type dedup struct {
+	window time.Duration
+	seen   map[string]time.Time
+	clean  time.Time
+	now    func() time.Time
+}
+
+func newDedup(window time.Duration) *dedup {
+	return &dedup{
+		window: window,
+		seen:   map[string]time.Time{},
+		clean:  time.Now(),
+		now:    time.Now,
+	}
+}
+
+// Check if the message has been seen before, recording it if not
+func (d *dedup) duplicate(message Message) bool {
+	now := d.now()
+	d.expire(now)
+
+	duplicate := false
+	for _, key := range dedupKeys(message) {
+		if t, ok := d.seen[key]; ok && now.Sub(t) < d.window {
+			duplicate = true
+			continue
+		}
+		d.seen[key] = now
+	}
+
+	return duplicate
+}
+
+// Forget the message so it is no longer a duplicate
+func (d *dedup) forget(message Message) {
+	for _, key := range dedupKeys(message) {
+		delete(d.seen, key)
+	}
+}
+
+func dedupKeys(message Message) []string {
+	keys := []string{"hash:" + hashText(message.Text)}
+	if message.ID != "" {
+		keys = append(keys, "id:"+message.ID)
+	}
+	return keys
+}
+
+// Forget messages older than the window, at most once per window
+func (d *dedup) expire(now time.Time) {
+	if now.Sub(d.clean) < d.window {
+		return
+	}
+	for key, t := range d.seen {
+		if now.Sub(t) >= d.window {
+			delete(d.seen, key)
+		}
+	}
+	d.clean = now
+}
+
+// Whitespace can differ between servers so it is ignored
+func hashText(text string) string {
+	sum := sha256.Sum256([]byte(strings.Join(strings.Fields(text), " ")))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,78 @@
+package ingest
+
+import (
+	"testing"
+	"time"
+)
+
+const testDedupText = "WFUS52 KRAH 262010\nTORRAH\n\nBULLETIN - EAS ACTIVATION REQUESTED\nTornado Warning\n"
+
+func newTestDedup(window time.Duration) (*dedup, *time.Time) {
+	now := time.Date(2025, 6, 26, 20, 10, 0, 0, time.UTC)
+	d := newDedup(window)
+	d.clean = now
+	d.now = func() time.Time { return now }
+	return d, &now
+}
+
+func TestDedupSameID(t *testing.T) {
+	d, _ := newTestDedup(10 * time.Minute)
+
+	// The same product from two servers, with the whitespace changed along the way
+	if d.duplicate(Message{ID: "12345.678", Text: testDedupText}) {
+		t.Fatalf("expected the first message to not be a duplicate")
+	}
+	if !d.duplicate(Message{ID: "12345.678", Text: testDedupText + "\r\n"}) {
+		t.Errorf("expected the second server's message to be a duplicate")
+	}
+	if !d.duplicate(Message{ID: "12345.678", Text: "different text"}) {
+		t.Errorf("expected a message with the same id to be a duplicate")
+	}
+}
+
+func TestDedupSameText(t *testing.T) {
+	d, _ := newTestDedup(10 * time.Minute)
+
+	if d.duplicate(Message{ID: "1.1", Text: testDedupText}) {
+		t.Fatalf("expected the first message to not be a duplicate")
+	}
+	if !d.duplicate(Message{ID: "2.2", Text: testDedupText}) {
+		t.Errorf("expected the same text with a different id to be a duplicate")
+	}
+	if !d.duplicate(Message{Text: "  " + testDedupText}) {
+		t.Errorf("expected the same text without an id to be a duplicate")
+	}
+	if d.duplicate(Message{ID: "3.3", Text: testDedupText + "Corrected\n"}) {
+		t.Errorf("expected different text with a different id to not be a duplicate")
+	}
+}
+
+func TestDedupExpiry(t *testing.T) {
+	d, now := newTestDedup(10 * time.Minute)
+
+	if d.duplicate(Message{ID: "1.1", Text: testDedupText}) {
+		t.Fatalf("expected the first message to not be a duplicate")
+	}
+
+	*now = now.Add(9 * time.Minute)
+	if !d.duplicate(Message{ID: "2.2", Text: testDedupText}) {
+		t.Errorf("expected a duplicate within the window")
+	}
+
+	// The window is from when a key was first seen, the id of the duplicate was first seen at 9 minutes
+	*now = now.Add(2 * time.Minute)
+	if d.duplicate(Message{ID: "1.1", Text: testDedupText}) {
+		t.Errorf("expected the id and text to have expired after the window")
+	}
+	if !d.duplicate(Message{ID: "2.2", Text: "other text"}) {
+		t.Errorf("expected the id seen 2 minutes ago to still be a duplicate")
+	}
+
+	*now = now.Add(10 * time.Minute)
+	if d.duplicate(Message{ID: "2.2", Text: "more text"}) {
+		t.Errorf("expected the id to have expired after the window")
+	}
+	if _, ok := d.seen["hash:"+hashText("other text")]; ok {
+		t.Errorf("expected expired keys to be forgotten")
+	}
+}
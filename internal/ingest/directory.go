@@ -0,0 +1,159 @@
+package ingest
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+/*
+Watches a directory for product files. Each file is removed once every product in it has been spooled, otherwise it is
+sent again on the next scan. Files that cannot be read or hold no products are moved to the failed directory inside it.
+A file may hold a single product or several SOH/ETX framed bulletins.
+Hidden and .tmp files are ignored so products can be written and renamed into place.
+*/
+// The directory files that cannot be sent are moved to
+const failedDir = "failed"
+
+type dirSource struct {
+	dir      string
+	interval time.Duration // How often the directory is checked
+}
+
+func newDirSource(dir string, interval time.Duration) *dirSource {
+	return &dirSource{
+		dir:      dir,
+		interval: interval,
+	}
+}
+
+func (d *dirSource) String() string {
+	return "dir " + d.dir
+}
+
+func (d *dirSource) Run(ctx context.Context, messages chan<- Message) {
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for {
+		err := d.scan(ctx, messages)
+		if err != nil {
+			slog.Error("failed to scan directory", "dir", d.dir, "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Send every product file in the directory, oldest first
+func (d *dirSource) scan(ctx context.Context, messages chan<- Message) error {
+	entries, err := os.ReadDir(d.dir)
+	if err != nil {
+		return err
+	}
+
+	type file struct {
+		path    string
+		modTime time.Time
+	}
+	files := []file{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasPrefix(name, ".") || strings.HasSuffix(name, ".tmp") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		// Skip anything that may still be being written
+		if time.Since(info.ModTime()) < time.Second {
+			continue
+		}
+		files = append(files, file{filepath.Join(d.dir, name), info.ModTime()})
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].modTime.Before(files[j].modTime) })
+
+	for _, f := range files {
+		data, err := os.ReadFile(f.path)
+		if err != nil {
+			slog.Error("failed to read product file", "path", f.path, "error", err)
+			d.fail(f.path)
+			continue
+		}
+
+		texts := []string{}
+		if bytes.IndexByte(data, soh) != -1 {
+			texts = splitBulletins(data)
+		} else if text := cleanBulletin(data); text != "" {
+			texts = append(texts, text)
+		}
+		if len(texts) == 0 {
+			slog.Error("no products in file", "path", f.path)
+			d.fail(f.path)
+			continue
+		}
+
+		acks := make(chan error, len(texts))
+		for _, text := range texts {
+			message := Message{
+				Text:       text,
+				ReceivedAt: time.Now(),
+				ack:        func(err error) { acks <- err },
+			}
+			select {
+			case messages <- message:
+			case <-ctx.Done():
+				return nil
+			}
+		}
+
+		spooled := true
+		for range texts {
+			select {
+			case err := <-acks:
+				if err != nil {
+					spooled = false
+				}
+			case <-ctx.Done():
+				return nil
+			}
+		}
+		if !spooled {
+			slog.Error("failed to spool product file, it will be sent again", "path", f.path)
+			continue
+		}
+
+		err = os.Remove(f.path)
+		if err != nil {
+			slog.Error("failed to remove product file", "path", f.path, "error", err)
+		}
+	}
+
+	return nil
+}
+
+// Move a file that cannot be sent out of the way so it is not tried again
+func (d *dirSource) fail(path string) {
+	failed := filepath.Join(d.dir, failedDir)
+	err := os.MkdirAll(failed, 0o755)
+	if err == nil {
+		err = os.Rename(path, filepath.Join(failed, filepath.Base(path)))
+	}
+	if err != nil {
+		slog.Error("failed to move product file to the failed directory", "path", path, "error", err)
+	}
+}
+
+func (d *dirSource) Close() error {
+	return nil
+}
@@ -0,0 +1,362 @@
+package ingest
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+/*
+EMWIN ByteBlaster protocol (version 1). Everything sent by the server is XORed with 0xFF.
+Files are sent in 1116 byte packets, each being 6 NUL bytes, an 80 byte header, 1024 bytes of data and 6 NUL bytes.
+The header describes which part of which file the data is, e.g.
+
+	/PFTOROKXNY.TXT/PN 1   /PT 2   /CS 63366 /FD6/26/2025 11:36:00 PM
+
+The checksum is the sum of the 1024 decoded data bytes, including the NUL bytes the last block of a file is padded with.
+*/
+const (
+	emwinSyncLength   = 6
+	emwinHeaderLength = 80
+	emwinDataLength   = 1024
+	emwinPacketLength = emwinSyncLength + emwinHeaderLength + emwinDataLength + emwinSyncLength
+)
+
+// The client has to log on again periodically or the server drops the connection
+const emwinLogonInterval = 2 * time.Minute
+
+var emwinHeaderRegexp = regexp.MustCompile(`^/PF(\S+)\s*/PN\s*(\d+)\s*/PT\s*(\d+)\s*/CS\s*(\d+)\s*/FD(.*)`)
+
+type emwinBlock struct {
+	filename string
+	number   int
+	total    int
+	checksum int
+	date     string
+	data     []byte
+}
+
+// A file being reassembled from its blocks
+type emwinFile struct {
+	blocks  map[int][]byte
+	total   int
+	started time.Time
+}
+
+// A connection to an EMWIN ByteBlaster server. Text products are sent, other files such as images are ignored.
+type emwinSource struct {
+	addr  string
+	email string // Sent to the server when logging on
+	files map[string]*emwinFile
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+func newEMWINSource(addr string, email string) *emwinSource {
+	return &emwinSource{
+		addr:  addr,
+		email: email,
+		files: map[string]*emwinFile{},
+	}
+}
+
+func (e *emwinSource) String() string {
+	return "emwin " + e.addr
+}
+
+// Receive files until the context is cancelled, reconnecting with exponential backoff whenever the connection is lost
+func (e *emwinSource) Run(ctx context.Context, messages chan<- Message) {
+	backoff := time.Second
+
+	for ctx.Err() == nil {
+		dialer := net.Dialer{Timeout: 30 * time.Second}
+		conn, err := dialer.DialContext(ctx, "tcp", e.addr)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			slog.Error("failed to connect to EMWIN", "addr", e.addr, "error", err, "retry", backoff)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+			backoff = min(backoff*2, maxBackoff)
+			continue
+		}
+		backoff = time.Second
+
+		e.mu.Lock()
+		e.conn = conn
+		e.mu.Unlock()
+
+		slog.Info("\033[32m *** EMWIN Connected *** \033[m", "addr", e.addr)
+
+		err = e.receive(ctx, conn, messages)
+		e.Close()
+
+		if ctx.Err() == nil {
+			slog.Warn("EMWIN connection lost, reconnecting", "addr", e.addr, "error", err)
+		}
+	}
+}
+
+// Read packets from the connection until it errors, logging on periodically
+func (e *emwinSource) receive(ctx context.Context, conn net.Conn, messages chan<- Message) error {
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		ticker := time.NewTicker(emwinLogonInterval)
+		defer ticker.Stop()
+		for {
+			err := e.logon(conn)
+			if err != nil {
+				slog.Error("failed to log on to EMWIN", "addr", e.addr, "error", err)
+			}
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+				conn.Close()
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	reader := bufio.NewReaderSize(xorReader{conn}, emwinPacketLength)
+
+	for {
+		// The server sends something at least every few seconds, so a long silence means the connection is dead
+		conn.SetReadDeadline(time.Now().Add(emwinLogonInterval))
+
+		block, err := readEMWINBlock(reader)
+		if err != nil {
+			return err
+		}
+		if block == nil {
+			continue
+		}
+
+		text, complete := e.add(block)
+		if !complete || text == "" {
+			continue
+		}
+
+		select {
+		case messages <- Message{Text: text, ReceivedAt: time.Now()}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (e *emwinSource) logon(conn net.Conn) error {
+	logon := []byte("ByteBlast Client|NM-" + e.email + "|V1")
+	for i := range logon {
+		logon[i] ^= 0xFF
+	}
+	_, err := conn.Write(logon)
+	return err
+}
+
+// Add the block to its file. Once the file is complete, any text product in it is returned.
+func (e *emwinSource) add(block *emwinBlock) (string, bool) {
+	// Forget files that were never completed
+	for key, file := range e.files {
+		if time.Since(file.started) > 10*time.Minute {
+			delete(e.files, key)
+		}
+	}
+
+	key := block.filename + " " + block.date
+	file, ok := e.files[key]
+	if !ok {
+		file = &emwinFile{
+			blocks:  map[int][]byte{},
+			total:   block.total,
+			started: time.Now(),
+		}
+		e.files[key] = file
+	}
+	file.blocks[block.number] = block.data
+
+	if len(file.blocks) < file.total {
+		return "", false
+	}
+	delete(e.files, key)
+
+	data := []byte{}
+	for i := 1; i <= file.total; i++ {
+		part, ok := file.blocks[i]
+		if !ok {
+			slog.Warn("EMWIN file has missing blocks", "file", block.filename)
+			return "", false
+		}
+		data = append(data, part...)
+	}
+
+	text, err := emwinText(block.filename, data)
+	if err != nil {
+		slog.Warn("failed to read EMWIN file", "file", block.filename, "error", err)
+		return "", false
+	}
+
+	return text, true
+}
+
+func (e *emwinSource) Close() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.conn == nil {
+		return nil
+	}
+	err := e.conn.Close()
+	e.conn = nil
+	return err
+}
+
+// Get the text product from a file. Text files are sent as is and zipped files are decompressed.
+// Returns an empty string for any other file.
+func emwinText(filename string, data []byte) (string, error) {
+	switch {
+	case strings.HasSuffix(filename, ".TXT"):
+		// Remove the NUL padding of the last block. Zipped files are not trimmed as they can end with NUL bytes.
+		return cleanBulletin(bytes.TrimRight(data, "\x00")), nil
+	case strings.HasSuffix(filename, ".ZIS"):
+		archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			return "", err
+		}
+		for _, f := range archive.File {
+			if !strings.HasSuffix(strings.ToUpper(f.Name), ".TXT") {
+				continue
+			}
+			r, err := f.Open()
+			if err != nil {
+				return "", err
+			}
+			defer r.Close()
+			text, err := io.ReadAll(r)
+			if err != nil {
+				return "", err
+			}
+			return cleanBulletin(text), nil
+		}
+	}
+	return "", nil
+}
+
+// Read the next packet from the stream. Returns a nil block if the packet is not a file block or is corrupt.
+func readEMWINBlock(reader *bufio.Reader) (*emwinBlock, error) {
+	// Find the start of the packet
+	nulls := 0
+	for nulls < emwinSyncLength {
+		b, err := reader.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		if b == 0 {
+			nulls++
+		} else {
+			nulls = 0
+		}
+	}
+
+	// The sync may be longer than expected, such as the end of the previous packet
+	for {
+		b, err := reader.Peek(1)
+		if err != nil {
+			return nil, err
+		}
+		if b[0] != 0 {
+			break
+		}
+		reader.ReadByte()
+	}
+
+	// Other packets such as the server list are skipped
+	prefix, err := reader.Peek(3)
+	if err != nil {
+		return nil, err
+	}
+	if string(prefix) != "/PF" {
+		return nil, nil
+	}
+
+	header := make([]byte, emwinHeaderLength)
+	_, err = io.ReadFull(reader, header)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := parseEMWINHeader(string(header))
+	if err != nil {
+		slog.Warn("invalid EMWIN header", "error", err)
+		return nil, nil
+	}
+
+	block.data = make([]byte, emwinDataLength)
+	_, err = io.ReadFull(reader, block.data)
+	if err != nil {
+		return nil, err
+	}
+
+	checksum := 0
+	for _, b := range block.data {
+		checksum += int(b)
+	}
+	if checksum != block.checksum {
+		slog.Warn("EMWIN block checksum mismatch", "file", block.filename, "block", block.number)
+		return nil, nil
+	}
+
+	return block, nil
+}
+
+func parseEMWINHeader(header string) (*emwinBlock, error) {
+	match := emwinHeaderRegexp.FindStringSubmatch(header)
+	if match == nil {
+		return nil, fmt.Errorf("could not parse header %q", strings.TrimSpace(header))
+	}
+
+	number, _ := strconv.Atoi(match[2])
+	total, _ := strconv.Atoi(match[3])
+	checksum, _ := strconv.Atoi(match[4])
+	if number < 1 || total < 1 || number > total {
+		return nil, fmt.Errorf("invalid block %d of %d", number, total)
+	}
+
+	return &emwinBlock{
+		filename: strings.ToUpper(match[1]),
+		number:   number,
+		total:    total,
+		checksum: checksum,
+		date:     strings.TrimSpace(match[5]),
+	}, nil
+}
+
+// Undoes the XOR encoding of the stream
+type xorReader struct {
+	reader io.Reader
+}
+
+func (x xorReader) Read(p []byte) (int, error) {
+	n, err := x.reader.Read(p)
+	for i := range p[:n] {
+		p[i] ^= 0xFF
+	}
+	return n, err
+}
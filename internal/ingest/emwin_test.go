@@ -0,0 +1,159 @@
+package ingest
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+)
+
+// Build an encoded packet for a block of a file. The checksum is taken from the data unless one is given.
+func emwinPacket(filename string, number int, total int, data []byte, checksum int) []byte {
+	block := make([]byte, emwinDataLength)
+	copy(block, data)
+	if checksum == -1 {
+		checksum = 0
+		for _, b := range block {
+			checksum += int(b)
+		}
+	}
+
+	header := fmt.Sprintf("/PF%-12s/PN %-3d/PT %-3d/CS %-5d/FD6/26/2025 11:36:00 PM", filename, number, total, checksum)
+	header += strings.Repeat(" ", emwinHeaderLength-len(header))
+
+	packet := make([]byte, emwinSyncLength)
+	packet = append(packet, header...)
+	packet = append(packet, block...)
+	packet = append(packet, make([]byte, emwinSyncLength)...)
+	for i := range packet {
+		packet[i] ^= 0xFF
+	}
+	return packet
+}
+
+// Send the packets through a connection and read back each complete text file
+func receiveEMWIN(t *testing.T, packets ...[]byte) []string {
+	t.Helper()
+
+	server, client := net.Pipe()
+	defer client.Close()
+
+	go func() {
+		defer server.Close()
+		for _, packet := range packets {
+			_, err := server.Write(packet)
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	e := newEMWINSource("pipe", "test@example.com")
+	reader := bufio.NewReaderSize(xorReader{client}, emwinPacketLength)
+	texts := []string{}
+	for {
+		block, err := readEMWINBlock(reader)
+		if err != nil {
+			// The pipe is closed once every packet has been read
+			return texts
+		}
+		if block == nil {
+			continue
+		}
+		text, complete := e.add(block)
+		if complete && text != "" {
+			texts = append(texts, text)
+		}
+	}
+}
+
+func TestEMWINMultipleBlocks(t *testing.T) {
+	text := "WFUS51 KOKX 262336\r\r\nTOROKX\r\r\n\r\r\n" + strings.Repeat("Tornado Warning\r\r\n", 100)
+	data := []byte(text)
+
+	// Packets that are not file blocks and extra sync bytes are skipped
+	serverList := []byte("/ServerList/emwin.example.com:2211|\x00")
+	for i := range serverList {
+		serverList[i] ^= 0xFF
+	}
+
+	texts := receiveEMWIN(t,
+		append(bytes.Repeat([]byte{0xFF}, emwinSyncLength), serverList...),
+		emwinPacket("TOROKXNY.TXT", 1, 2, data[:emwinDataLength], -1),
+		[]byte{0xFF, 0xFF},
+		emwinPacket("TOROKXNY.TXT", 2, 2, data[emwinDataLength:], -1),
+	)
+
+	if len(texts) != 1 {
+		t.Fatalf("expected 1 product, got %d", len(texts))
+	}
+	expected := cleanBulletin(data)
+	if texts[0] != expected {
+		t.Errorf("expected %q, got %q", expected, texts[0])
+	}
+}
+
+func TestEMWINZipped(t *testing.T) {
+	text := "WWUS81 KOKX 262336\r\r\nSPSOKX\r\r\n\r\r\nSpecial Weather Statement\r\r\n"
+
+	var buffer bytes.Buffer
+	archive := zip.NewWriter(&buffer)
+	f, err := archive.Create("SPSOKXNY.TXT")
+	if err != nil {
+		t.Fatalf("failed to create zip: %v", err)
+	}
+	f.Write([]byte(text))
+	archive.Close()
+	if buffer.Len() > emwinDataLength {
+		t.Fatalf("expected the zip to fit in one block, got %d bytes", buffer.Len())
+	}
+
+	texts := receiveEMWIN(t,
+		emwinPacket("SPSOKXNY.ZIS", 1, 1, buffer.Bytes(), -1),
+		// Images are ignored
+		emwinPacket("RADOKXNY.GIF", 1, 1, []byte("GIF89a"), -1),
+	)
+
+	if len(texts) != 1 {
+		t.Fatalf("expected 1 product, got %d", len(texts))
+	}
+	expected := cleanBulletin([]byte(text))
+	if texts[0] != expected {
+		t.Errorf("expected %q, got %q", expected, texts[0])
+	}
+}
+
+func TestEMWINCorruptBlock(t *testing.T) {
+	text := "WFUS51 KOKX 262336\r\r\nTOROKX\r\r\n\r\r\n" + strings.Repeat("Tornado Warning\r\r\n", 100)
+	data := []byte(text)
+
+	first := emwinPacket("TOROKXNY.TXT", 1, 2, data[:emwinDataLength], -1)
+	second := emwinPacket("TOROKXNY.TXT", 2, 2, data[emwinDataLength:], -1)
+
+	// Flip a byte of the data after the header so the checksum no longer matches
+	corrupt := bytes.Clone(second)
+	corrupt[emwinSyncLength+emwinHeaderLength+10] ^= 0x01
+
+	texts := receiveEMWIN(t, first, corrupt)
+	if len(texts) != 0 {
+		t.Fatalf("expected the file to be incomplete without the corrupt block, got %q", texts)
+	}
+
+	// The file is completed when the block is sent again
+	texts = receiveEMWIN(t, first, corrupt, second)
+	if len(texts) != 1 {
+		t.Fatalf("expected 1 product once the block was resent, got %d", len(texts))
+	}
+	if texts[0] != cleanBulletin(data) {
+		t.Errorf("expected %q, got %q", cleanBulletin(data), texts[0])
+	}
+
+	// A header checksum that does not match the data is also dropped
+	texts = receiveEMWIN(t, emwinPacket("SPSOKXNY.TXT", 1, 1, []byte("WWUS81 KOKX 262336\r\r\nSPSOKX\r\r\n"), 12345))
+	if len(texts) != 0 {
+		t.Fatalf("expected the block with a wrong checksum to be dropped, got %q", texts)
+	}
+}
@@ -0,0 +1,151 @@
+package ingest
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	soh = 0x01 // Start of a bulletin
+	etx = 0x03 // End of a bulletin
+)
+
+// The NOAAPort sequence number that precedes the WMO header
+var bulletinSequence = regexp.MustCompile(`^\d{3,5} *\n`)
+
+/*
+Reads concatenated text bulletins framed with SOH/ETX as written by LDM or NOAAPort, e.g.
+
+	\x01\r\r\n123 \r\r\nWFUS51 KOKX 262336\r\r\nTOROKX\r\r\n...\x03
+
+The file is followed like tail -f, so it may be a file LDM appends to or a named pipe.
+*/
+type ldmSource struct {
+	path string
+
+	mu   sync.Mutex
+	file *os.File
+}
+
+func newLDMSource(path string) *ldmSource {
+	return &ldmSource{
+		path: path,
+	}
+}
+
+func (l *ldmSource) String() string {
+	return "ldm " + l.path
+}
+
+func (l *ldmSource) Run(ctx context.Context, messages chan<- Message) {
+	file := os.Stdin
+	if l.path != "-" {
+		var err error
+		file, err = os.Open(l.path)
+		if err != nil {
+			slog.Error("failed to open ldm feed", "path", l.path, "error", err)
+			return
+		}
+	}
+
+	l.mu.Lock()
+	l.file = file
+	l.mu.Unlock()
+
+	defer l.Close()
+
+	l.read(ctx, file, messages)
+}
+
+// Read bulletins until the context is cancelled or the feed fails. A bulletin may be split across several reads.
+func (l *ldmSource) read(ctx context.Context, feed io.Reader, messages chan<- Message) {
+	reader := bufio.NewReader(feed)
+	bulletin := []byte{}
+
+	for ctx.Err() == nil {
+		data, err := reader.ReadBytes(etx)
+		bulletin = append(bulletin, data...)
+
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				if ctx.Err() == nil {
+					slog.Error("failed to read ldm feed", "path", l.path, "error", err)
+				}
+				return
+			}
+			// Wait for more to be written
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(time.Second):
+			}
+			continue
+		}
+
+		for _, text := range splitBulletins(bulletin) {
+			select {
+			case messages <- Message{Text: text, ReceivedAt: time.Now()}:
+			case <-ctx.Done():
+				return
+			}
+		}
+		bulletin = bulletin[:0]
+	}
+}
+
+func (l *ldmSource) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.file == nil || l.file == os.Stdin {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
+
+// Split SOH/ETX framed bulletins into their text. Data outside of the frames is ignored.
+func splitBulletins(data []byte) []string {
+	bulletins := []string{}
+
+	for {
+		start := bytes.IndexByte(data, soh)
+		if start == -1 {
+			break
+		}
+		end := bytes.IndexByte(data[start:], etx)
+		if end == -1 {
+			break
+		}
+		// A bulletin that is missing its ETX runs into the next one, so only keep the last
+		if next := bytes.LastIndexByte(data[start+1:start+end], soh); next != -1 {
+			start += next + 1
+			end -= next + 1
+		}
+
+		text := cleanBulletin(data[start+1 : start+end])
+		if text != "" {
+			bulletins = append(bulletins, text)
+		}
+		data = data[start+end+1:]
+	}
+
+	return bulletins
+}
+
+// Normalise the line endings and remove the NOAAPort sequence number
+func cleanBulletin(data []byte) string {
+	text := strings.ReplaceAll(string(data), "\r", "")
+	text = strings.TrimLeft(text, "\n")
+	text = bulletinSequence.ReplaceAllString(text, "")
+	return strings.TrimSpace(text)
+}
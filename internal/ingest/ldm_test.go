@@ -0,0 +1,264 @@
+package ingest
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const (
+	testTOR = "WFUS52 KRAH 262010\nTORRAH\n\nBULLETIN - EAS ACTIVATION REQUESTED\nTornado Warning"
+	testSVS = "WWUS52 KRAH 262030\nSVSRAH\n\nSevere Weather Statement"
+)
+
+func TestSplitBulletins(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		expected []string
+	}{
+		{
+			name:     "noaaport frame",
+			data:     "\x01\r\r\n123 \r\r\nWFUS52 KRAH 262010\r\r\nTORRAH\r\r\n\r\r\nBULLETIN - EAS ACTIVATION REQUESTED\r\r\nTornado Warning\r\r\n\x03",
+			expected: []string{testTOR},
+		},
+		{
+			name:     "no sequence number",
+			data:     "\x01" + testTOR + "\x03",
+			expected: []string{testTOR},
+		},
+		{
+			name:     "five digit sequence number",
+			data:     "\x01\n12345\n" + testTOR + "\n\x03",
+			expected: []string{testTOR},
+		},
+		{
+			name:     "number in the text is kept",
+			data:     "\x01\n001 \n" + testSVS + "\n500\n\x03",
+			expected: []string{testSVS + "\n500"},
+		},
+		{
+			name:     "several frames with data between",
+			data:     "junk\x01" + testTOR + "\x03\r\n\x01" + testSVS + "\x03trailing",
+			expected: []string{testTOR, testSVS},
+		},
+		{
+			name:     "missing ETX at the end",
+			data:     "\x01" + testTOR + "\x03\x01" + testSVS,
+			expected: []string{testTOR},
+		},
+		{
+			name:     "missing ETX before the next frame",
+			data:     "\x01" + testTOR + "\x01" + testSVS + "\x03",
+			expected: []string{testSVS},
+		},
+		{
+			name:     "empty frame",
+			data:     "\x01\r\r\n\x03\x01" + testSVS + "\x03",
+			expected: []string{testSVS},
+		},
+		{
+			name:     "no frames",
+			data:     testTOR,
+			expected: []string{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			bulletins := splitBulletins([]byte(test.data))
+			if len(bulletins) != len(test.expected) {
+				t.Fatalf("expected %d bulletins, got %d: %q", len(test.expected), len(bulletins), bulletins)
+			}
+			for i := range test.expected {
+				if bulletins[i] != test.expected[i] {
+					t.Errorf("expected bulletin %d to be %q, got %q", i, test.expected[i], bulletins[i])
+				}
+			}
+		})
+	}
+}
+
+func TestLDMPartialReads(t *testing.T) {
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("failed to create pipe: %v", err)
+	}
+	defer reader.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	messages := make(chan Message, 10)
+	done := make(chan struct{})
+	go func() {
+		newLDMSource("-").read(ctx, reader, messages)
+		close(done)
+	}()
+
+	// The frames are split across writes, including in the middle of a frame
+	chunks := []string{
+		"\x01\r\r\n001 \r\r\nWFUS52 KRAH 26",
+		"2010\r\r\nTORRAH\r\r\n\r\r\nBULLETIN - EAS ACTIVATION REQUESTED\r\r\nTornado Warning\r\r\n\x03\x01",
+		testSVS,
+		"\x03",
+	}
+	for _, chunk := range chunks {
+		_, err := writer.Write([]byte(chunk))
+		if err != nil {
+			t.Fatalf("failed to write: %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	for _, expected := range []string{testTOR, testSVS} {
+		select {
+		case message := <-messages:
+			if message.Text != expected {
+				t.Errorf("expected %q, got %q", expected, message.Text)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("timed out waiting for %q", expected)
+		}
+	}
+
+	writer.Close()
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(3 * time.Second):
+		t.Fatalf("reader did not stop after the context was cancelled")
+	}
+}
+
+func TestDirectoryScan(t *testing.T) {
+	dir := t.TempDir()
+
+	// A link to a file that does not exist cannot be read. The time of a link cannot be set so wait until it is old enough.
+	unreadable := filepath.Join(dir, "missing.txt")
+	err := os.Symlink(filepath.Join(dir, "nowhere"), unreadable)
+	if err != nil {
+		t.Fatalf("failed to create link: %v", err)
+	}
+	time.Sleep(1100 * time.Millisecond)
+
+	old := time.Now().Add(-time.Minute)
+
+	write := func(name string, data string, modTime time.Time) string {
+		path := filepath.Join(dir, name)
+		err := os.WriteFile(path, []byte(data), 0o644)
+		if err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+		err = os.Chtimes(path, modTime, modTime)
+		if err != nil {
+			t.Fatalf("failed to set the time of %s: %v", name, err)
+		}
+		return path
+	}
+
+	single := write("tor.txt", testTOR+"\r\n", old.Add(-time.Second))
+	framed := write("feed.ldm", "\x01"+testSVS+"\x03\x01"+testTOR+"\x03", old)
+	tmp := write("product.tmp", testTOR, old)
+	hidden := write(".product", testTOR, old)
+	fresh := write("fresh.txt", testSVS, time.Now())
+
+	empty := write("empty.txt", "\r\n", old.Add(time.Second))
+
+	texts := scanDir(t, newDirSource(dir, time.Second), func(text string) error { return nil })
+
+	// Oldest file first
+	expected := []string{testTOR, testSVS, testTOR}
+	if len(texts) != len(expected) {
+		t.Fatalf("expected %d messages, got %d: %q", len(expected), len(texts), texts)
+	}
+	for i := range expected {
+		if texts[i] != expected[i] {
+			t.Errorf("expected message %d to be %q, got %q", i, expected[i], texts[i])
+		}
+	}
+
+	for _, path := range []string{single, framed, empty, unreadable} {
+		if _, err := os.Lstat(path); !os.IsNotExist(err) {
+			t.Errorf("expected %s to be removed once sent", filepath.Base(path))
+		}
+	}
+	for _, path := range []string{tmp, hidden, fresh} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("expected %s to be left alone: %v", filepath.Base(path), err)
+		}
+	}
+	for _, path := range []string{empty, unreadable} {
+		if _, err := os.Lstat(filepath.Join(dir, failedDir, filepath.Base(path))); err != nil {
+			t.Errorf("expected %s to be moved to the failed directory: %v", filepath.Base(path), err)
+		}
+	}
+}
+
+func TestDirectoryScanNotSpooled(t *testing.T) {
+	dir := t.TempDir()
+	old := time.Now().Add(-time.Minute)
+
+	path := filepath.Join(dir, "feed.ldm")
+	err := os.WriteFile(path, []byte("\x01"+testSVS+"\x03\x01"+testTOR+"\x03"), 0o644)
+	if err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	err = os.Chtimes(path, old, old)
+	if err != nil {
+		t.Fatalf("failed to set the time of the file: %v", err)
+	}
+
+	source := newDirSource(dir, time.Second)
+
+	// One of the products could not be spooled so the whole file is kept
+	texts := scanDir(t, source, func(text string) error {
+		if text == testTOR {
+			return errors.New("disk full")
+		}
+		return nil
+	})
+	if len(texts) != 2 {
+		t.Fatalf("expected 2 messages, got %d: %q", len(texts), texts)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("expected the file to be kept: %v", err)
+	}
+
+	// Sent again and removed on the next scan
+	texts = scanDir(t, source, func(text string) error { return nil })
+	if len(texts) != 2 {
+		t.Fatalf("expected the file to be sent again, got %q", texts)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("expected the file to be removed once spooled")
+	}
+}
+
+// Scan the directory, acknowledging each message with the result of spool, and return the texts sent
+func scanDir(t *testing.T, d *dirSource, spool func(text string) error) []string {
+	t.Helper()
+
+	messages := make(chan Message)
+	done := make(chan error)
+	go func() {
+		err := d.scan(context.Background(), messages)
+		close(messages)
+		done <- err
+	}()
+
+	texts := []string{}
+	for message := range messages {
+		texts = append(texts, message.Text)
+		message.acknowledge(spool(message.Text))
+	}
+
+	err := <-done
+	if err != nil {
+		t.Fatalf("failed to scan: %v", err)
+	}
+	return texts
+}
@@ -0,0 +1,45 @@
+package ingest
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+)
+
+// The state of the ingest server
+type Status struct {
+	NWWS   []NWWSStatus `json:"nwws"`
+	Rabbit string       `json:"rabbit"`
+	Spool  int          `json:"spool"` // The number of messages waiting to be published
+}
+
+func (server *Server) Status() Status {
+	status := Status{
+		NWWS:   []NWWSStatus{},
+		Rabbit: server.rabbit.state(),
+		Spool:  server.spool.depth(),
+	}
+	for _, source := range server.sources {
+		if n, ok := source.(*nwws); ok {
+			status.NWWS = append(status.NWWS, n.status())
+		}
+	}
+	return status
+}
+
+// Serves the status of the server as JSON on /status
+func (server *Server) newMonitor(addr string) *http.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/status", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		err := json.NewEncoder(w).Encode(server.Status())
+		if err != nil {
+			slog.Error("failed to write status", "error", err)
+		}
+	})
+
+	return &http.Server{
+		Addr:    addr,
+		Handler: mux,
+	}
+}
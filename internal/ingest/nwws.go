@@ -0,0 +1,288 @@
+package ingest
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/xmppo/go-xmpp"
+)
+
+const (
+	StateConnecting   = "connecting"
+	StateConnected    = "connected"
+	StateDisconnected = "disconnected"
+)
+
+// The longest wait between reconnection attempts
+const maxBackoff = 5 * time.Minute
+
+// The part of an XMPP client the NWWS-OI connection uses
+type xmppClient interface {
+	Recv() (interface{}, error)
+	Close() error
+}
+
+// A connection to the NWWS-OI that reconnects when it is closed or goes quiet
+type nwws struct {
+	config   XmppConfig
+	stale    time.Duration // Reconnect if no messages are received for this long
+	sequence sequence
+	dial     func() (xmppClient, error)
+
+	mu     sync.Mutex
+	client xmppClient
+	state  string
+	last   time.Time
+}
+
+// The state of a NWWS-OI connection
+type NWWSStatus struct {
+	Server      string    `json:"server"`
+	State       string    `json:"state"`
+	LastMessage time.Time `json:"last_message"`
+}
+
+func newNWWS(config XmppConfig, stale time.Duration) *nwws {
+	if stale <= 0 {
+		stale = 5 * time.Minute
+	}
+
+	n := &nwws{
+		config: config,
+		stale:  stale,
+		state:  StateDisconnected,
+	}
+	n.dial = n.connect
+	return n
+}
+
+// Connect to the server and join the room
+func (n *nwws) connect() (xmppClient, error) {
+	conf := n.config
+
+	options := xmpp.Options{
+		Host:      conf.Server,
+		User:      conf.User + "@" + conf.serverName(),
+		Password:  conf.Pass,
+		Resource:  conf.Resource,
+		NoTLS:     true,
+		StartTLS:  true,
+		TLSConfig: conf.tlsConfig(),
+		Debug:     false, // Set to true if you want to see debug information
+		Session:   true,
+		// Default timeout for the connection
+		DialTimeout: 60 * time.Second,
+	}
+
+	client, err := options.NewClient()
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = client.SendOrg(fmt.Sprintf(`<presence xml:lang='en' from='%s@%s' to='%s@%s/%s'><x></x></presence>`, conf.User, conf.Server, conf.Resource, conf.Room, conf.User))
+	if err != nil {
+		client.Close()
+		return nil, err
+	}
+
+	return client, nil
+}
+
+// Receive messages until the context is cancelled, reconnecting with exponential backoff whenever the connection is lost
+func (n *nwws) Run(ctx context.Context, messages chan<- Message) {
+	backoff := time.Second
+
+	for ctx.Err() == nil {
+		n.setState(StateConnecting, nil)
+
+		client, err := n.dial()
+		if err != nil {
+			slog.Error("failed to connect to NWWS-OI", "server", n.config.Server, "error", err, "retry", backoff)
+			n.setState(StateDisconnected, nil)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+			backoff = min(backoff*2, maxBackoff)
+			continue
+		}
+		backoff = time.Second
+
+		n.setState(StateConnected, client)
+		slog.Info("\033[32m *** NWWS-OI Connected *** \033[m", "server", n.config.Server)
+
+		err = n.receive(ctx, client, messages)
+		client.Close()
+		n.setState(StateDisconnected, nil)
+
+		if ctx.Err() == nil {
+			slog.Warn("NWWS-OI connection lost, reconnecting", "server", n.config.Server, "error", err)
+		}
+	}
+}
+
+// Receive messages from the client until it errors. A watchdog closes the client if it goes stale or the context is cancelled.
+func (n *nwws) receive(ctx context.Context, client xmppClient, messages chan<- Message) error {
+	n.touch()
+
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		ticker := time.NewTicker(n.stale / 4)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+				client.Close()
+				return
+			case <-ticker.C:
+				if time.Since(n.lastMessage()) > n.stale {
+					slog.Warn("NWWS-OI connection is stale", "server", n.config.Server, "last", n.lastMessage())
+					client.Close()
+					return
+				}
+			}
+		}
+	}()
+
+	for {
+		chat, err := client.Recv()
+		if err != nil {
+			return err
+		}
+
+		switch v := chat.(type) {
+		case xmpp.Chat:
+			for _, elem := range v.OtherElem {
+				if elem.XMLName.Local == "x" {
+					text := strings.ReplaceAll(elem.String(), "\n\n", "\n")
+					message := Message{
+						Text:       text,
+						ReceivedAt: time.Now(),
+					}
+					message.setAttributes(elem.Attr)
+
+					missed := n.sequence.check(message.ID)
+					if missed > 0 {
+						slog.Warn("NWWS-OI sequence gap, products were missed", "missed", missed, "id", message.ID)
+					}
+
+					n.touch()
+
+					select {
+					case messages <- message:
+					case <-ctx.Done():
+						return ctx.Err()
+					}
+				}
+			}
+		}
+	}
+}
+
+func (n *nwws) setState(state string, client xmppClient) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.state = state
+	n.client = client
+}
+
+func (n *nwws) touch() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.last = time.Now()
+}
+
+func (n *nwws) lastMessage() time.Time {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.last
+}
+
+func (n *nwws) status() NWWSStatus {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return NWWSStatus{
+		Server:      n.config.Server,
+		State:       n.state,
+		LastMessage: n.last,
+	}
+}
+
+func (n *nwws) String() string {
+	return "nwws " + n.config.Server
+}
+
+// Close the current connection, if any
+func (n *nwws) Close() error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.client == nil {
+		return nil
+	}
+	return n.client.Close()
+}
+
+// Copy the NWWS-OI attributes of the <x> element into the message, e.g.
+// <x xmlns="nwws-oi" cccc="KOKX" ttaaii="WFUS51" issue="2025-06-26T23:36:00Z" awipsid="TOROKX" id="14425.12345">
+func (message *Message) setAttributes(attrs []xml.Attr) {
+	for _, attr := range attrs {
+		switch attr.Name.Local {
+		case "cccc":
+			message.CCCC = attr.Value
+		case "ttaaii":
+			message.TTAAII = attr.Value
+		case "awipsid":
+			message.AWIPSID = strings.TrimSpace(attr.Value)
+		case "id":
+			message.ID = attr.Value
+		case "issue":
+			issue, err := time.Parse(time.RFC3339, attr.Value)
+			if err != nil {
+				slog.Warn("failed to parse NWWS-OI issue time", "issue", attr.Value, "error", err)
+				continue
+			}
+			message.Issue = issue.UTC()
+		}
+	}
+}
+
+// Tracks the NWWS-OI sequence ids to find products that were missed.
+// The id is the server's process id and a sequence number, e.g. 14425.12345, so a new process id restarts the sequence.
+type sequence struct {
+	process string
+	number  int
+}
+
+// Check the id follows the previous one, returning how many products were missed
+func (seq *sequence) check(id string) int {
+	process, numberString, found := strings.Cut(id, ".")
+	if !found {
+		return 0
+	}
+	number, err := strconv.Atoi(numberString)
+	if err != nil {
+		return 0
+	}
+
+	missed := 0
+	if process == seq.process && number > seq.number+1 {
+		missed = number - seq.number - 1
+	}
+
+	seq.process = process
+	seq.number = number
+
+	return missed
+}
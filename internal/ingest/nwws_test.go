@@ -0,0 +1,267 @@
+package ingest
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/xmppo/go-xmpp"
+)
+
+func attrs(pairs ...string) []xml.Attr {
+	result := []xml.Attr{}
+	for i := 0; i+1 < len(pairs); i += 2 {
+		result = append(result, xml.Attr{Name: xml.Name{Local: pairs[i]}, Value: pairs[i+1]})
+	}
+	return result
+}
+
+func TestSetAttributes(t *testing.T) {
+	tests := []struct {
+		name     string
+		attrs    []xml.Attr
+		expected Message
+	}{
+		{
+			name:  "all attributes",
+			attrs: attrs("xmlns", "nwws-oi", "cccc", "KOKX", "ttaaii", "WFUS51", "issue", "2025-06-26T23:36:00Z", "awipsid", "TOROKX", "id", "14425.12345"),
+			expected: Message{
+				CCCC:    "KOKX",
+				TTAAII:  "WFUS51",
+				AWIPSID: "TOROKX",
+				Issue:   time.Date(2025, 6, 26, 23, 36, 0, 0, time.UTC),
+				ID:      "14425.12345",
+			},
+		},
+		{
+			name:     "missing attributes",
+			attrs:    attrs("cccc", "KOKX", "id", "14425.12345"),
+			expected: Message{CCCC: "KOKX", ID: "14425.12345"},
+		},
+		{
+			name:     "no attributes",
+			attrs:    nil,
+			expected: Message{},
+		},
+		{
+			name:     "padded AWIPS identifier",
+			attrs:    attrs("awipsid", "SPSOKX "),
+			expected: Message{AWIPSID: "SPSOKX"},
+		},
+		{
+			name:     "bad issue time",
+			attrs:    attrs("ttaaii", "WFUS51", "issue", "26/06/2025 23:36"),
+			expected: Message{TTAAII: "WFUS51"},
+		},
+		{
+			name:     "issue time with an offset",
+			attrs:    attrs("issue", "2025-06-26T19:36:00-04:00"),
+			expected: Message{Issue: time.Date(2025, 6, 26, 23, 36, 0, 0, time.UTC)},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			message := Message{Text: "text"}
+			message.setAttributes(test.attrs)
+			test.expected.Text = "text"
+
+			if !message.Issue.Equal(test.expected.Issue) {
+				t.Errorf("expected issue %v, got %v", test.expected.Issue, message.Issue)
+			}
+			message.Issue, test.expected.Issue = time.Time{}, time.Time{}
+			if !reflect.DeepEqual(message, test.expected) {
+				t.Errorf("expected %+v, got %+v", test.expected, message)
+			}
+		})
+	}
+}
+
+func TestSequence(t *testing.T) {
+	tests := []struct {
+		name   string
+		ids    []string
+		missed []int
+	}{
+		{"first message", []string{"14425.12345"}, []int{0}},
+		{"in order", []string{"14425.1", "14425.2", "14425.3"}, []int{0, 0, 0}},
+		{"gap", []string{"14425.1", "14425.2", "14425.6"}, []int{0, 0, 3}},
+		{"server restart", []string{"14425.100", "20001.1", "20001.2"}, []int{0, 0, 0}},
+		{"gap after restart", []string{"14425.100", "20001.1", "20001.3"}, []int{0, 0, 1}},
+		{"repeated", []string{"14425.5", "14425.5", "14425.6"}, []int{0, 0, 0}},
+		{"backwards", []string{"14425.5", "14425.2", "14425.4"}, []int{0, 0, 1}},
+		{"missing id", []string{"14425.1", "", "14425.3"}, []int{0, 0, 1}},
+		{"malformed id", []string{"14425.1", "14425.x", "abc", "14425.2"}, []int{0, 0, 0, 0}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			seq := sequence{}
+			for i, id := range test.ids {
+				missed := seq.check(id)
+				if missed != test.missed[i] {
+					t.Errorf("expected %d missed at %s, got %d", test.missed[i], id, missed)
+				}
+			}
+		})
+	}
+}
+
+// A client that receives the given stanzas and then blocks until it is closed
+type fakeClient struct {
+	stanzas chan interface{}
+	closed  chan struct{}
+	once    sync.Once
+}
+
+func newFakeClient(stanzas ...interface{}) *fakeClient {
+	c := &fakeClient{
+		stanzas: make(chan interface{}, len(stanzas)),
+		closed:  make(chan struct{}),
+	}
+	for _, stanza := range stanzas {
+		c.stanzas <- stanza
+	}
+	return c
+}
+
+func (c *fakeClient) Recv() (interface{}, error) {
+	select {
+	case stanza := <-c.stanzas:
+		return stanza, nil
+	case <-c.closed:
+		return nil, errors.New("connection closed")
+	}
+}
+
+func (c *fakeClient) Close() error {
+	c.once.Do(func() { close(c.closed) })
+	return nil
+}
+
+func TestNWWSStaleReconnect(t *testing.T) {
+	n := newNWWS(XmppConfig{Server: "nwws-oi.example.com"}, 40*time.Millisecond)
+
+	product := xmpp.Chat{OtherElem: []xmpp.XMLElement{{
+		XMLName:  xml.Name{Local: "x"},
+		Attr:     attrs("cccc", "KOKX", "awipsid", "TOROKX", "id", "14425.1"),
+		InnerXML: "WFUS51 KOKX 262336\nTOROKX\n",
+	}}}
+
+	clients := make(chan *fakeClient, 10)
+	n.dial = func() (xmppClient, error) {
+		client := newFakeClient(product)
+		clients <- client
+		return client, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	messages := make(chan Message, 10)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		n.Run(ctx, messages)
+	}()
+
+	first := <-clients
+	select {
+	case message := <-messages:
+		if message.AWIPSID != "TOROKX" {
+			t.Errorf("expected TOROKX, got %+v", message)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected a message from the first connection")
+	}
+
+	// Nothing else is received so the watchdog closes the connection and a new one is made
+	select {
+	case <-clients:
+	case <-time.After(time.Second):
+		t.Fatal("expected the stale connection to be replaced")
+	}
+	select {
+	case <-first.closed:
+	default:
+		t.Error("expected the stale connection to be closed")
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("expected Run to return once the context was cancelled")
+	}
+	if state := n.status().State; state != StateDisconnected {
+		t.Errorf("expected %s after stopping, got %s", StateDisconnected, state)
+	}
+}
+
+func TestStatusEndpoint(t *testing.T) {
+	spool, err := newSpool(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create spool: %v", err)
+	}
+	err = spool.push(Message{Text: "WFUS51 KOKX 262336\nTOROKX\n", ID: "14425.1"})
+	if err != nil {
+		t.Fatalf("failed to spool message: %v", err)
+	}
+
+	last := time.Date(2025, 6, 26, 23, 36, 0, 0, time.UTC)
+	connected := newNWWS(XmppConfig{Server: "nwws-oi.example.com"}, 0)
+	connected.state = StateConnected
+	connected.last = last
+	disconnected := newNWWS(XmppConfig{Server: "nwws-oi2.example.com"}, 0)
+
+	server := &Server{
+		sources: []Source{connected, disconnected},
+		rabbit:  newRabbitPublisher("amqp://localhost"),
+		spool:   spool,
+	}
+
+	recorder := httptest.NewRecorder()
+	server.newMonitor(":0").Handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/status", nil))
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", recorder.Code)
+	}
+	if contentType := recorder.Header().Get("Content-Type"); contentType != "application/json" {
+		t.Errorf("expected a JSON response, got %s", contentType)
+	}
+
+	var status struct {
+		NWWS []struct {
+			Server      string    `json:"server"`
+			State       string    `json:"state"`
+			LastMessage time.Time `json:"last_message"`
+		} `json:"nwws"`
+		Rabbit string `json:"rabbit"`
+		Spool  int    `json:"spool"`
+	}
+	err = json.Unmarshal(recorder.Body.Bytes(), &status)
+	if err != nil {
+		t.Fatalf("failed to decode status: %v", err)
+	}
+
+	if len(status.NWWS) != 2 {
+		t.Fatalf("expected 2 NWWS-OI connections, got %+v", status.NWWS)
+	}
+	if status.NWWS[0].Server != "nwws-oi.example.com" || status.NWWS[0].State != StateConnected || !status.NWWS[0].LastMessage.Equal(last) {
+		t.Errorf("unexpected status of the first connection: %+v", status.NWWS[0])
+	}
+	if status.NWWS[1].Server != "nwws-oi2.example.com" || status.NWWS[1].State != StateDisconnected || !status.NWWS[1].LastMessage.IsZero() {
+		t.Errorf("unexpected status of the second connection: %+v", status.NWWS[1])
+	}
+	if status.Rabbit != RabbitDisconnected {
+		t.Errorf("expected rabbit to be %s, got %s", RabbitDisconnected, status.Rabbit)
+	}
+	if status.Spool != 1 {
+		t.Errorf("expected 1 spooled message, got %d", status.Spool)
+	}
+}
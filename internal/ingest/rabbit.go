@@ -0,0 +1,194 @@
+package ingest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+const (
+	RabbitConnected    = "connected"
+	RabbitDisconnected = "disconnected"
+)
+
+// A RabbitMQ connection in confirm mode. Each publish waits for the broker to confirm the message.
+// Messages are published as mandatory so a message that cannot be routed to a queue is returned and treated as a failure.
+type rabbitPublisher struct {
+	url string
+
+	mu      sync.Mutex
+	conn    *amqp.Connection
+	channel *amqp.Channel
+	returns chan amqp.Return
+	sent    uint64 // Used to match returned messages to the publish
+}
+
+func newRabbitPublisher(url string) *rabbitPublisher {
+	return &rabbitPublisher{
+		url: url,
+	}
+}
+
+// Connect to RabbitMQ and declare the exchange and queue
+func (r *rabbitPublisher) connect() error {
+	conn, err := amqp.Dial(r.url)
+	if err != nil {
+		return err
+	}
+
+	ch, err := conn.Channel()
+	if err != nil {
+		conn.Close()
+		return err
+	}
+
+	err = ch.ExchangeDeclare(
+		exchangeOut, // name
+		"direct",    // type
+		true,        // durable
+		false,       // auto-deleted
+		false,       // internal
+		false,       // no-wait
+		nil,         // arguments
+	)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+
+	_, err = ch.QueueDeclare(
+		queueOut, // name
+		true,     // durable
+		false,    // delete when unused
+		false,    // exclusive
+		false,    // no-wait
+		nil,      // arguments
+	)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+
+	err = ch.QueueBind(
+		queueOut,      // queue name
+		routingKeyOut, // routing key
+		exchangeOut,   // exchange
+		false,         // no-wait
+		nil,           // arguments
+	)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+
+	err = ch.Confirm(false)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+
+	// A return is always delivered before the confirmation of the same message
+	returns := ch.NotifyReturn(make(chan amqp.Return, 1))
+
+	r.mu.Lock()
+	r.conn = conn
+	r.channel = ch
+	r.returns = returns
+	r.mu.Unlock()
+
+	slog.Info("\033[32m *** RabbitMQ connected *** \033[m")
+
+	return nil
+}
+
+func (r *rabbitPublisher) connected() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.conn != nil && !r.conn.IsClosed()
+}
+
+// Publish the message and wait for it to be confirmed by the broker. Messages are published one at a time.
+func (r *rabbitPublisher) publish(ctx context.Context, body []byte) error {
+	r.mu.Lock()
+	ch := r.channel
+	returns := r.returns
+	r.sent++
+	id := strconv.FormatUint(r.sent, 10)
+	r.mu.Unlock()
+	if ch == nil {
+		return errors.New("not connected to rabbitmq")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	confirm, err := ch.PublishWithDeferredConfirmWithContext(ctx,
+		exchangeOut,   // exchange
+		routingKeyOut, // routing key
+		true,          // mandatory
+		false,         // immediate
+		amqp.Publishing{
+			ContentType:  "application/json",
+			DeliveryMode: amqp.Persistent,
+			MessageId:    id,
+			Body:         body,
+		})
+	if err != nil {
+		return err
+	}
+
+	acked, err := confirm.WaitContext(ctx)
+	if err != nil {
+		return err
+	}
+	if !acked {
+		return errors.New("message was not acknowledged by rabbitmq")
+	}
+
+	// Unroutable messages are still acknowledged, so check if it was returned
+	for {
+		select {
+		case ret, ok := <-returns:
+			if !ok {
+				return errors.New("channel closed while publishing")
+			}
+			if ret.MessageId != id {
+				// Left over from an earlier publish that was already treated as failed
+				continue
+			}
+			return fmt.Errorf("message was returned by rabbitmq: %d %s", ret.ReplyCode, ret.ReplyText)
+		default:
+			return nil
+		}
+	}
+}
+
+func (r *rabbitPublisher) state() string {
+	if r.connected() {
+		return RabbitConnected
+	}
+	return RabbitDisconnected
+}
+
+// Close the connection, if any
+func (r *rabbitPublisher) close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.conn == nil {
+		return nil
+	}
+	err := r.conn.Close()
+	r.conn = nil
+	r.channel = nil
+	r.returns = nil
+	if errors.Is(err, amqp.ErrClosed) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,169 @@
+package ingest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/metdatasystem/mds-awips/pkg/awips"
+)
+
+type ReplayConfig struct {
+	Rate      float64   // Products published per second, no limit if 0
+	IssueTime bool      // Use the product's issue time as the time it was received
+	Since     time.Time // Only products issued at or after this time, if set
+	Until     time.Time // Only products issued before this time, if set
+}
+
+func (config ReplayConfig) filtered() bool {
+	return !config.Since.IsZero() || !config.Until.IsZero()
+}
+
+// Check if a product issued at the time is within the filter
+func (config ReplayConfig) includes(issued time.Time) bool {
+	if !config.Since.IsZero() && issued.Before(config.Since) {
+		return false
+	}
+	if !config.Until.IsZero() && !issued.Before(config.Until) {
+		return false
+	}
+	return true
+}
+
+/*
+Publish archived products into the parse pipeline. Each path may be a file or a directory, which is walked in name order.
+A file may hold a single product or several concatenated SOH/ETX framed bulletins, as in LDM and IEM archives.
+Products without an issue time are skipped when filtering by time. Returns how many products were published.
+*/
+func Replay(ctx context.Context, paths []string, config ReplayConfig) (int, error) {
+	files, err := replayFiles(paths)
+	if err != nil {
+		return 0, err
+	}
+
+	url := os.Getenv("RABBIT")
+	if url == "" {
+		return 0, fmt.Errorf("rabbit missing in config")
+	}
+
+	rabbit := newRabbitPublisher(url)
+	err = rabbit.connect()
+	if err != nil {
+		return 0, err
+	}
+	defer rabbit.close()
+
+	return replay(ctx, files, config, rabbit.publish)
+}
+
+// Publish the products in the files in order
+func replay(ctx context.Context, files []string, config ReplayConfig, publish func(ctx context.Context, body []byte) error) (int, error) {
+	var ticker *time.Ticker
+	if config.Rate > 0 {
+		ticker = time.NewTicker(time.Duration(float64(time.Second) / config.Rate))
+		defer ticker.Stop()
+	}
+
+	published := 0
+	for _, path := range files {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return published, err
+		}
+
+		texts := []string{}
+		if bytes.IndexByte(data, soh) != -1 {
+			texts = splitBulletins(data)
+		} else if text := cleanBulletin(data); text != "" {
+			texts = append(texts, text)
+		}
+
+		for _, text := range texts {
+			var issued time.Time
+			if config.IssueTime || config.filtered() {
+				issued, err = awips.GetIssuedTime(text)
+				if err != nil || issued.IsZero() {
+					issued = time.Time{}
+					slog.Warn("could not find issue time", "path", path, "error", err)
+				}
+			}
+			if config.filtered() && (issued.IsZero() || !config.includes(issued)) {
+				continue
+			}
+
+			if ticker != nil {
+				select {
+				case <-ctx.Done():
+					return published, ctx.Err()
+				case <-ticker.C:
+				}
+			} else if ctx.Err() != nil {
+				return published, ctx.Err()
+			}
+
+			message := Message{
+				Text:       text,
+				ReceivedAt: time.Now(),
+			}
+			if config.IssueTime && !issued.IsZero() {
+				message.ReceivedAt = issued.UTC()
+			}
+
+			body, err := json.Marshal(message)
+			if err != nil {
+				return published, err
+			}
+
+			err = publish(ctx, body)
+			if err != nil {
+				return published, err
+			}
+			published++
+		}
+
+		slog.Debug("replayed file", "path", path, "products", len(texts))
+	}
+
+	return published, nil
+}
+
+// Expand the paths into the files they contain, in order
+func replayFiles(paths []string) ([]string, error) {
+	files := []string{}
+
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+
+		found := []string{}
+		err = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() {
+				found = append(found, p)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		sort.Strings(found)
+		files = append(files, found...)
+	}
+
+	return files, nil
+}
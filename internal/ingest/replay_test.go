@@ -0,0 +1,175 @@
+package ingest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+const (
+	replayTOR = "WFUS52 KRAH 262010\nTORRAH\n\nTornado Warning\nNational Weather Service Raleigh NC\n410 PM EDT Thu Jun 26 2025\n"
+	replaySVS = "WWUS52 KRAH 270130\nSVSRAH\n\nSevere Weather Statement\nNational Weather Service Raleigh NC\n930 PM EDT Thu Jun 26 2025\n"
+	replayRWR = "SXUS42 KRAH 270200\nRWRRAH\n\nNo issue time\n"
+	replayAFD = "FXUS62 KRAH 281600\nAFDRAH\n\nArea Forecast Discussion\nNational Weather Service Raleigh NC\n1200 PM EDT Sat Jun 28 2025\n"
+)
+
+// Write an archive of products across nested directories and return the files to replay
+func replayArchive(t *testing.T) []string {
+	t.Helper()
+	dir := t.TempDir()
+
+	write := func(name string, data string) {
+		path := filepath.Join(dir, name)
+		err := os.MkdirAll(filepath.Dir(path), 0o755)
+		if err != nil {
+			t.Fatalf("failed to create directory: %v", err)
+		}
+		err = os.WriteFile(path, []byte(data), 0o644)
+		if err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	// Written out of order to check the files are replayed in name order
+	write("20250628/afd.txt", replayAFD)
+	write("20250626/tor.txt", replayTOR)
+	write("20250627/feed.ldm", "\x01"+replaySVS+"\x03\x01"+replayRWR+"\x03")
+
+	files, err := replayFiles([]string{dir})
+	if err != nil {
+		t.Fatalf("failed to list files: %v", err)
+	}
+	return files
+}
+
+func TestReplay(t *testing.T) {
+	files := replayArchive(t)
+
+	tests := []struct {
+		name     string
+		config   ReplayConfig
+		expected []string
+	}{
+		{
+			name:     "everything",
+			config:   ReplayConfig{},
+			expected: []string{replayTOR, replaySVS, replayRWR, replayAFD},
+		},
+		{
+			name:     "since",
+			config:   ReplayConfig{Since: time.Date(2025, 6, 27, 0, 0, 0, 0, time.UTC)},
+			expected: []string{replaySVS, replayAFD},
+		},
+		{
+			name:     "until",
+			config:   ReplayConfig{Until: time.Date(2025, 6, 27, 1, 30, 0, 0, time.UTC)},
+			expected: []string{replayTOR},
+		},
+		{
+			name: "between",
+			config: ReplayConfig{
+				Since: time.Date(2025, 6, 26, 20, 10, 0, 0, time.UTC),
+				Until: time.Date(2025, 6, 28, 0, 0, 0, 0, time.UTC),
+			},
+			expected: []string{replayTOR, replaySVS},
+		},
+		{
+			name:     "nothing in range",
+			config:   ReplayConfig{Since: time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)},
+			expected: []string{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			texts := []string{}
+			published, err := replay(context.Background(), files, test.config, func(ctx context.Context, body []byte) error {
+				message := Message{}
+				err := json.Unmarshal(body, &message)
+				if err != nil {
+					t.Fatalf("failed to decode message: %v", err)
+				}
+				texts = append(texts, message.Text)
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			expected := []string{}
+			for _, text := range test.expected {
+				expected = append(expected, cleanBulletin([]byte(text)))
+			}
+			if !slices.Equal(texts, expected) {
+				t.Errorf("expected %q, got %q", expected, texts)
+			}
+			if published != len(expected) {
+				t.Errorf("expected %d published, got %d", len(expected), published)
+			}
+		})
+	}
+}
+
+func TestReplayIssueTime(t *testing.T) {
+	files := replayArchive(t)
+	start := time.Now()
+
+	received := []time.Time{}
+	_, err := replay(context.Background(), files, ReplayConfig{IssueTime: true}, func(ctx context.Context, body []byte) error {
+		message := Message{}
+		err := json.Unmarshal(body, &message)
+		if err != nil {
+			t.Fatalf("failed to decode message: %v", err)
+		}
+		received = append(received, message.ReceivedAt)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(received) != 4 {
+		t.Fatalf("expected 4 products, got %d", len(received))
+	}
+	expected := []time.Time{
+		time.Date(2025, 6, 26, 20, 10, 0, 0, time.UTC),
+		time.Date(2025, 6, 27, 1, 30, 0, 0, time.UTC),
+		{}, // Without an issue time the product keeps the time it was replayed
+		time.Date(2025, 6, 28, 16, 0, 0, 0, time.UTC),
+	}
+	for i, e := range expected {
+		if e.IsZero() {
+			if received[i].Before(start) {
+				t.Errorf("expected product %d to be received now, got %v", i, received[i])
+			}
+			continue
+		}
+		if !received[i].Equal(e) {
+			t.Errorf("expected product %d to be received at %v, got %v", i, e, received[i])
+		}
+	}
+}
+
+func TestReplayPublishError(t *testing.T) {
+	files := replayArchive(t)
+
+	calls := 0
+	published, err := replay(context.Background(), files, ReplayConfig{}, func(ctx context.Context, body []byte) error {
+		calls++
+		if calls == 2 {
+			return errors.New("connection closed")
+		}
+		return nil
+	})
+	if err == nil {
+		t.Fatal("expected the publish error to be returned")
+	}
+	if published != 1 {
+		t.Errorf("expected 1 product to be published before the error, got %d", published)
+	}
+}
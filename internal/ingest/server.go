@@ -3,15 +3,13 @@ package ingest
 import (
 	"context"
 	"crypto/tls"
-	"encoding/json"
 	"fmt"
 	"log/slog"
+	"net/http"
 	"os"
 	"strings"
+	"sync"
 	"time"
-
-	amqp "github.com/rabbitmq/amqp091-go"
-	"github.com/xmppo/go-xmpp"
 )
 
 const (
@@ -29,203 +27,280 @@ type XmppConfig struct {
 }
 
 type Server struct {
-	xmpp     *xmpp.Client
-	messages chan Message
-	rabbit   *amqp.Connection
-	out      *amqp.Channel
-	ctx      context.Context
-	cancel   context.CancelFunc
+	sources []Source
+	dedup   *dedup
+	rabbit  *rabbitPublisher
+	spool   *spool
+	monitor *http.Server
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup // Spooling and forwarding, which finish what they are doing before shutting down
 }
 
 type Message struct {
 	Text       string
 	ReceivedAt time.Time
+	CCCC       string    // The issuing office in the WMO header
+	TTAAII     string    // The data type in the WMO header
+	AWIPSID    string    // The AWIPS identifier, empty if the product does not have one
+	Issue      time.Time // The issue time of the product
+	ID         string    // The NWWS-OI sequence id
+
+	ack func(err error) // Called once the message is spooled or dropped as a duplicate, if set
 }
 
-func New() (*Server, error) {
-	nwwsConfig := XmppConfig{
-		Server:   os.Getenv("NWWSOI_SERVER") + ":5222",
-		Room:     os.Getenv("NWWSOI_ROOM"),
-		User:     os.Getenv("NWWSOI_USER"),
-		Pass:     os.Getenv("NWWSOI_PASS"),
-		Resource: os.Getenv("NWWSOI_RESOURCE"),
+// Tell the source whether the message was spooled
+func (message Message) acknowledge(err error) {
+	if message.ack != nil {
+		message.ack(err)
 	}
+}
 
-	err := nwwsConfig.check()
+func New() (*Server, error) {
+	sources, err := newSources()
 	if err != nil {
 		return nil, err
 	}
 
-	xmpp.DefaultConfig = &tls.Config{
-		ServerName:         nwwsConfig.serverName(),
-		InsecureSkipVerify: false,
+	// Products received from more than one source within this window are only sent once
+	window := 10 * time.Minute
+	if s := os.Getenv("NWWSOI_DEDUP_WINDOW"); s != "" {
+		window, err = time.ParseDuration(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid NWWSOI_DEDUP_WINDOW: %w", err)
+		}
 	}
 
-	options := xmpp.Options{
-		Host:        nwwsConfig.Server,
-		User:        nwwsConfig.User + "@" + nwwsConfig.serverName(),
-		Password:    nwwsConfig.Pass,
-		Resource:    nwwsConfig.Resource,
-		NoTLS:       true,
-		StartTLS:    true,
-		Debug:       false, // Set to true if you want to see debug information
-		Session:     true,
-		DialTimeout: 60 * time.Second,
+	rabbitURL := os.Getenv("RABBIT")
+	if rabbitURL == "" {
+		return nil, fmt.Errorf("rabbit missing in config")
 	}
 
-	client, err := options.NewClient()
-	if err != nil {
-		return nil, err
+	// Messages are spooled to disk until RabbitMQ confirms them
+	spoolDir := os.Getenv("INGEST_SPOOL")
+	if spoolDir == "" {
+		spoolDir = "spool"
 	}
-
-	slog.Info("\033[32m *** NWWS-OI Connected *** \033[m")
-
-	_, err = client.SendOrg(fmt.Sprintf(`<presence xml:lang='en' from='%s@%s' to='%s@%s/%s'><x></x></presence>`, nwwsConfig.User, nwwsConfig.Server, nwwsConfig.Resource, nwwsConfig.Room, nwwsConfig.User))
+	spool, err := newSpool(spoolDir)
 	if err != nil {
 		return nil, err
 	}
+	if depth := spool.depth(); depth > 0 {
+		slog.Info("found spooled messages from a previous run", "depth", depth)
+	}
 
-	rabbit, err := amqp.Dial(os.Getenv("RABBIT"))
-	if err != nil {
-		return nil, err
+	ctx, cancel := context.WithCancel(context.Background())
+	server := &Server{
+		sources: sources,
+		dedup:   newDedup(window),
+		rabbit:  newRabbitPublisher(rabbitURL),
+		spool:   spool,
+		ctx:     ctx,
+		cancel:  cancel,
 	}
 
-	ch, err := rabbit.Channel()
-	if err != nil {
-		return nil, err
+	if addr := os.Getenv("INGEST_MONITOR"); addr != "" {
+		server.monitor = server.newMonitor(addr)
 	}
 
-	err = ch.ExchangeDeclare(
-		exchangeOut, // name
-		"direct",    // type
-		true,        // durable
-		false,       // auto-deleted
-		false,       // internal
-		false,       // no-wait
-		nil,         // arguments
-	)
-	if err != nil {
-		return nil, err
+	return server, nil
+}
+
+/*
+Run the server
+*/
+func (server *Server) Run() error {
+
+	if server.monitor != nil {
+		go func() {
+			err := server.monitor.ListenAndServe()
+			if err != nil && err != http.ErrServerClosed {
+				slog.Error("monitor stopped", "error", err)
+			}
+		}()
 	}
 
-	_, err = ch.QueueDeclare(
-		queueOut, // name
-		true,     // durable
-		false,    // delete when unused
-		false,    // exclusive
-		false,    // no-wait
-		nil,      // arguments
-	)
-	if err != nil {
-		return nil, err
+	// Every source feeds the same stream which is de-duplicated before being sent
+	received := make(chan Message)
+	var wg sync.WaitGroup
+
+	for _, source := range server.sources {
+		slog.Info("\033[32m *** Starting source *** \033[m", "source", source.String())
+		wg.Add(1)
+		go func(source Source) {
+			defer wg.Done()
+			source.Run(server.ctx, received)
+			slog.Info("source stopped", "source", source.String())
+		}(source)
 	}
 
-	slog.Info("\033[32m *** RabbitMQ connected *** \033[m")
+	go func() {
+		wg.Wait()
+		close(received)
+	}()
 
-	// TODO: Monitoring server
+	server.wg.Add(2)
+	go func(server *Server) {
+		defer server.wg.Done()
+		for message := range received {
+			if server.dedup.duplicate(message) {
+				message.acknowledge(nil)
+				continue
+			}
+			err := server.spool.push(message)
+			if err != nil {
+				// Forget the message so it is not dropped as a duplicate if the source sends it again
+				server.dedup.forget(message)
+				slog.Error("failed to spool message", "error", err, "id", message.ID)
+			}
+			message.acknowledge(err)
+		}
+	}(server)
 
-	ctx, cancel := context.WithCancel(context.Background())
-	server := &Server{
-		xmpp:     client,
-		messages: make(chan Message),
-		rabbit:   rabbit,
-		out:      ch,
-		ctx:      ctx,
-		cancel:   cancel,
+	go func() {
+		defer server.wg.Done()
+		server.forward()
+	}()
+
+	return nil
+}
+
+func (server *Server) Shutdown() {
+	server.cancel()
+	for _, source := range server.sources {
+		err := source.Close()
+		if err != nil {
+			slog.Error("failed to close source", "error", err, "source", source.String())
+		}
 	}
 
-	return server, nil
+	// Every received message is spooled and any publish in flight is confirmed or left in the spool
+	server.wg.Wait()
+
+	if server.monitor != nil {
+		err := server.monitor.Close()
+		if err != nil {
+			slog.Error("failed to close monitor", "error", err)
+		}
+	}
+
+	err := server.rabbit.close()
+	if err != nil {
+		slog.Error("failed to close rabbit connection", "error", err)
+	}
 }
 
 /*
-Run the server
+Publish the spooled messages in order until the context is cancelled. A message is only removed from the spool once
+RabbitMQ has confirmed it, so if RabbitMQ is unavailable the messages wait in the spool while reconnecting with backoff.
 */
-func (server *Server) Run() error {
+func (server *Server) forward() {
+	backoff := time.Second
 
-	go func(server *Server) {
-		slog.Info("\033[32m *** Starting NWWS-OI *** \033[m")
-		for {
-			select {
-			// Stop and cleanup on cancel
-			case <-server.ctx.Done():
-				close(server.messages)
-				slog.Info("NWWS-OI Stopped")
-				return
-			// Receive messages
-			default:
-				// Get message
-				chat, err := server.xmpp.Recv()
-				if err != nil {
-					slog.Error("xmpp receive error", "error", err.Error())
-					continue
-				}
-
-				// Parse and send message
-				switch v := chat.(type) {
-				case xmpp.Chat:
-					for _, elem := range v.OtherElem {
-						if elem.XMLName.Local == "x" {
-							text := strings.ReplaceAll(elem.String(), "\n\n", "\n")
-							server.messages <- Message{
-								Text:       text,
-								ReceivedAt: time.Now(),
-							}
-							// TODO: Implement monitor
-							// nwws.last = time.Now().UTC()
-						}
-					}
+	for server.ctx.Err() == nil {
+		if !server.rabbit.connected() {
+			server.rabbit.close()
+			err := server.rabbit.connect()
+			if err != nil {
+				slog.Error("failed to connect to rabbitmq", "error", err, "retry", backoff, "spooled", server.spool.depth())
+				select {
+				case <-server.ctx.Done():
+					return
+				case <-time.After(backoff):
 				}
+				backoff = min(backoff*2, maxBackoff)
+				continue
 			}
 		}
-	}(server)
 
-	go func(server *Server) {
-		for message := range server.messages {
-			body, err := json.Marshal(message)
+		number, body, ok, err := server.spool.peek()
+		if err != nil {
+			// The message cannot be read back so there is nothing to publish
+			slog.Error("failed to read spooled message, dropping it", "error", err, "number", number)
+			err = server.spool.remove(number)
 			if err != nil {
-				slog.Error(err.Error())
+				slog.Error("failed to remove spooled message", "error", err, "number", number)
+			}
+			continue
+		}
+		if !ok {
+			select {
+			case <-server.ctx.Done():
 				return
+			case <-server.spool.notify:
 			}
+			continue
+		}
 
-			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-			defer cancel()
-
-			err = server.out.PublishWithContext(ctx,
-				exchangeOut,   // exchange
-				routingKeyOut, // routing key
-				false,         // mandatory
-				false,         // immediate
-				amqp.Publishing{
-					ContentType: "application/json",
-					Body:        body,
-				})
-			if err != nil {
-				slog.Error(err.Error())
+		err = server.rabbit.publish(server.ctx, body)
+		if err != nil {
+			if server.ctx.Err() != nil {
+				return
+			}
+			// Drop the connection so it is reestablished, the message stays in the spool
+			slog.Error("failed to publish message", "error", err, "retry", backoff, "spooled", server.spool.depth())
+			server.rabbit.close()
+			select {
+			case <-server.ctx.Done():
 				return
+			case <-time.After(backoff):
 			}
+			backoff = min(backoff*2, maxBackoff)
+			continue
 		}
-	}(server)
+		backoff = time.Second
 
-	return nil
+		err = server.spool.remove(number)
+		if err != nil {
+			slog.Error("failed to remove spooled message", "error", err, "number", number)
+		}
+	}
 }
 
-func (server *Server) Shutdown() {
-	server.cancel()
-	err := server.xmpp.Close()
-	if err != nil {
-		slog.Error("failed to close xmpp connection", "error", err)
-	}
+/*
+Read the NWWS-OI connections from the environment. NWWSOI_SERVER may be a comma separated list of servers to connect to at once.
+Each connection needs its own resource, so NWWSOI_RESOURCE is either a list of the same length or a single resource
+that is suffixed with the connection number.
+*/
+func xmppConfigs() ([]XmppConfig, error) {
+	servers := strings.Split(os.Getenv("NWWSOI_SERVER"), ",")
+	resources := strings.Split(os.Getenv("NWWSOI_RESOURCE"), ",")
 
-	err = server.out.Close()
-	if err != nil {
-		slog.Error("failed to close out channel", "error", err)
+	if len(resources) != 1 && len(resources) != len(servers) {
+		return nil, fmt.Errorf("expected 1 or %d NWWS-OI resources, got %d", len(servers), len(resources))
 	}
 
-	err = server.rabbit.Close()
-	if err != nil {
-		slog.Error("failed to close rabbit connection", "error", err)
+	configs := []XmppConfig{}
+	for i, s := range servers {
+		resource := strings.TrimSpace(resources[0])
+		if len(resources) > 1 {
+			resource = strings.TrimSpace(resources[i])
+		} else if len(servers) > 1 {
+			resource = fmt.Sprintf("%s-%d", resource, i+1)
+		}
+
+		conf := XmppConfig{
+			Server:   strings.TrimSpace(s) + ":5222",
+			Room:     os.Getenv("NWWSOI_ROOM"),
+			User:     os.Getenv("NWWSOI_USER"),
+			Pass:     os.Getenv("NWWSOI_PASS"),
+			Resource: resource,
+		}
+
+		// An empty server would otherwise pass the check with the port appended
+		if strings.TrimSpace(s) == "" {
+			conf.Server = ""
+		}
+
+		err := conf.check()
+		if err != nil {
+			return nil, err
+		}
+
+		configs = append(configs, conf)
 	}
 
+	return configs, nil
 }
 
 func (conf *XmppConfig) check() error {
@@ -251,3 +326,10 @@ func (conf *XmppConfig) check() error {
 func (conf *XmppConfig) serverName() string {
 	return strings.Split(conf.Server, ":")[0]
 }
+
+func (conf *XmppConfig) tlsConfig() *tls.Config {
+	return &tls.Config{
+		ServerName:         conf.serverName(),
+		InsecureSkipVerify: false,
+	}
+}
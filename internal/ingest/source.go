@@ -0,0 +1,95 @@
+package ingest
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// A source of products for the server, such as the NWWS-OI or files on disk
+type Source interface {
+	// Send products to messages until the context is cancelled
+	Run(ctx context.Context, messages chan<- Message)
+	// Stop the source, interrupting anything it is waiting on
+	Close() error
+	// A name for the source in logs
+	String() string
+}
+
+/*
+Create the sources listed in INGEST_SOURCES, a comma separated list of:
+  - nwws: the NWWS-OI, configured by the NWWSOI_* variables
+  - dir: product files dropped in INGEST_DIR
+  - ldm: SOH/ETX framed bulletins read from INGEST_LDM, a file or pipe written to by LDM. "-" reads from stdin.
+  - emwin: the EMWIN ByteBlaster servers in INGEST_EMWIN, a comma separated list of host:port. INGEST_EMWIN_EMAIL is sent when logging on.
+
+Defaults to the NWWS-OI only.
+*/
+func newSources() ([]Source, error) {
+	names := os.Getenv("INGEST_SOURCES")
+	if names == "" {
+		names = "nwws"
+	}
+
+	sources := []Source{}
+	for _, name := range strings.Split(names, ",") {
+		switch strings.TrimSpace(name) {
+		case "nwws":
+			n, err := nwwsSources()
+			if err != nil {
+				return nil, err
+			}
+			sources = append(sources, n...)
+		case "dir":
+			dir := os.Getenv("INGEST_DIR")
+			if dir == "" {
+				return nil, fmt.Errorf("INGEST_DIR missing in config")
+			}
+			sources = append(sources, newDirSource(dir, 5*time.Second))
+		case "ldm":
+			path := os.Getenv("INGEST_LDM")
+			if path == "" {
+				return nil, fmt.Errorf("INGEST_LDM missing in config")
+			}
+			sources = append(sources, newLDMSource(path))
+		case "emwin":
+			addrs := os.Getenv("INGEST_EMWIN")
+			if addrs == "" {
+				return nil, fmt.Errorf("INGEST_EMWIN missing in config")
+			}
+			for _, addr := range strings.Split(addrs, ",") {
+				sources = append(sources, newEMWINSource(strings.TrimSpace(addr), os.Getenv("INGEST_EMWIN_EMAIL")))
+			}
+		default:
+			return nil, fmt.Errorf("unknown source %s", name)
+		}
+	}
+
+	return sources, nil
+}
+
+// Create a NWWS-OI connection for each configured server
+func nwwsSources() ([]Source, error) {
+	configs, err := xmppConfigs()
+	if err != nil {
+		return nil, err
+	}
+
+	// Reconnect if nothing has been received for a while. The NWWS-OI is rarely quiet for more than a minute.
+	stale := 5 * time.Minute
+	if s := os.Getenv("NWWSOI_STALE"); s != "" {
+		stale, err = time.ParseDuration(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid NWWSOI_STALE: %w", err)
+		}
+	}
+
+	sources := []Source{}
+	for _, conf := range configs {
+		sources = append(sources, newNWWS(conf, stale))
+	}
+
+	return sources, nil
+}
@@ -0,0 +1,182 @@
+package ingest
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// A write-ahead spool of messages on disk. Every message is written to the spool before it is published
+// and only removed once RabbitMQ has confirmed it, so nothing is lost while RabbitMQ is unavailable or the server restarts.
+// Each message is stored in its own file named by a sequence number so they are published in the order they were received.
+type spool struct {
+	dir string
+
+	mu      sync.Mutex
+	pending []uint64 // Sequence numbers of the spooled messages, oldest first
+	next    uint64
+	notify  chan struct{}
+}
+
+func newSpool(dir string) (*spool, error) {
+	err := os.MkdirAll(dir, 0o755)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create spool directory: %w", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read spool directory: %w", err)
+	}
+
+	s := &spool{
+		dir:    dir,
+		notify: make(chan struct{}, 1),
+	}
+
+	// Pick up anything left over from the last run
+	for _, entry := range entries {
+		// A message that was being written when the server stopped was never spooled
+		if strings.HasSuffix(entry.Name(), ".json.tmp") {
+			os.Remove(filepath.Join(dir, entry.Name()))
+			continue
+		}
+
+		name, found := strings.CutSuffix(entry.Name(), ".json")
+		if !found || entry.IsDir() {
+			continue
+		}
+		number, err := strconv.ParseUint(name, 10, 64)
+		if err != nil {
+			continue
+		}
+		s.pending = append(s.pending, number)
+	}
+	sort.Slice(s.pending, func(i, j int) bool { return s.pending[i] < s.pending[j] })
+
+	if len(s.pending) > 0 {
+		s.next = s.pending[len(s.pending)-1] + 1
+	}
+
+	return s, nil
+}
+
+// Write the message to disk
+func (s *spool) push(message Message) error {
+	body, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	number := s.next
+	path := s.path(number)
+
+	// Write to a temporary file first so a partially written message is never published
+	tmp := path + ".tmp"
+	file, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(body)
+	if err == nil {
+		err = file.Sync()
+	}
+	if e := file.Close(); err == nil {
+		err = e
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	err = os.Rename(tmp, path)
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	// The rename is only durable once the directory itself is synced
+	err = syncDir(s.dir)
+	if err != nil {
+		os.Remove(path)
+		return err
+	}
+
+	s.next++
+	s.pending = append(s.pending, number)
+
+	select {
+	case s.notify <- struct{}{}:
+	default:
+	}
+
+	return nil
+}
+
+// Read the oldest message in the spool. Returns false if the spool is empty.
+func (s *spool) peek() (uint64, []byte, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.pending) == 0 {
+		return 0, nil, false, nil
+	}
+
+	number := s.pending[0]
+	body, err := os.ReadFile(s.path(number))
+	if err != nil {
+		return number, nil, true, err
+	}
+
+	return number, body, true, nil
+}
+
+// Remove a message from the spool once it has been published
+func (s *spool) remove(number uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, n := range s.pending {
+		if n == number {
+			s.pending = append(s.pending[:i], s.pending[i+1:]...)
+			break
+		}
+	}
+
+	err := os.Remove(s.path(number))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// The number of messages waiting to be published
+func (s *spool) depth() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.pending)
+}
+
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	err = d.Sync()
+	if e := d.Close(); err == nil {
+		err = e
+	}
+	return err
+}
+
+func (s *spool) path(number uint64) string {
+	return filepath.Join(s.dir, fmt.Sprintf("%020d.json", number))
+}
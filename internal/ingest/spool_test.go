@@ -0,0 +1,162 @@
+package ingest
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func pushTexts(t *testing.T, s *spool, texts ...string) {
+	t.Helper()
+	for _, text := range texts {
+		err := s.push(Message{Text: text})
+		if err != nil {
+			t.Fatalf("failed to push %s: %v", text, err)
+		}
+	}
+}
+
+// Read and remove every message in the spool, oldest first
+func drain(t *testing.T, s *spool) []string {
+	t.Helper()
+	texts := []string{}
+	for {
+		number, body, ok, err := s.peek()
+		if err != nil {
+			t.Fatalf("failed to peek: %v", err)
+		}
+		if !ok {
+			return texts
+		}
+		var message Message
+		err = json.Unmarshal(body, &message)
+		if err != nil {
+			t.Fatalf("failed to unmarshal spooled message: %v", err)
+		}
+		texts = append(texts, message.Text)
+		err = s.remove(number)
+		if err != nil {
+			t.Fatalf("failed to remove: %v", err)
+		}
+	}
+}
+
+func TestSpoolPush(t *testing.T) {
+	dir := t.TempDir()
+	s, err := newSpool(dir)
+	if err != nil {
+		t.Fatalf("failed to create spool: %v", err)
+	}
+
+	pushTexts(t, s, "one")
+
+	if s.depth() != 1 {
+		t.Errorf("expected depth 1, got %d", s.depth())
+	}
+	select {
+	case <-s.notify:
+	default:
+		t.Errorf("expected a notification after a push")
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("failed to read spool directory: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Name() != "00000000000000000000.json" {
+		t.Fatalf("expected a single spooled file, got %v", entries)
+	}
+
+	// Peeking leaves the message in the spool until it is removed
+	number, _, ok, err := s.peek()
+	if err != nil || !ok {
+		t.Fatalf("expected a spooled message, got %v %v", ok, err)
+	}
+	if s.depth() != 1 {
+		t.Errorf("expected peek to not remove the message")
+	}
+	err = s.remove(number)
+	if err != nil {
+		t.Fatalf("failed to remove: %v", err)
+	}
+	if s.depth() != 0 {
+		t.Errorf("expected depth 0, got %d", s.depth())
+	}
+	if _, err := os.Stat(s.path(number)); !os.IsNotExist(err) {
+		t.Errorf("expected the spooled file to be removed")
+	}
+}
+
+func TestSpoolOrder(t *testing.T) {
+	s, err := newSpool(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create spool: %v", err)
+	}
+
+	texts := []string{"one", "two", "three", "four", "five", "six", "seven", "eight", "nine", "ten", "eleven"}
+	pushTexts(t, s, texts...)
+
+	got := drain(t, s)
+	if len(got) != len(texts) {
+		t.Fatalf("expected %d messages, got %d", len(texts), len(got))
+	}
+	for i := range texts {
+		if got[i] != texts[i] {
+			t.Errorf("expected message %d to be %s, got %s", i, texts[i], got[i])
+		}
+	}
+}
+
+func TestSpoolRecovery(t *testing.T) {
+	dir := t.TempDir()
+	s, err := newSpool(dir)
+	if err != nil {
+		t.Fatalf("failed to create spool: %v", err)
+	}
+
+	pushTexts(t, s, "one", "two", "three")
+
+	// The first message was published before the crash
+	number, _, _, _ := s.peek()
+	err = s.remove(number)
+	if err != nil {
+		t.Fatalf("failed to remove: %v", err)
+	}
+
+	// A message that was being written when the process crashed, and something that is not part of the spool
+	err = os.WriteFile(filepath.Join(dir, "00000000000000000003.json.tmp"), []byte(`{"Text":"par`), 0o644)
+	if err != nil {
+		t.Fatalf("failed to write partial message: %v", err)
+	}
+	err = os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("not a message"), 0o644)
+	if err != nil {
+		t.Fatalf("failed to write other file: %v", err)
+	}
+
+	// Restart
+	s, err = newSpool(dir)
+	if err != nil {
+		t.Fatalf("failed to reopen spool: %v", err)
+	}
+	if s.depth() != 2 {
+		t.Fatalf("expected 2 recovered messages, got %d", s.depth())
+	}
+	if _, err := os.Stat(filepath.Join(dir, "00000000000000000003.json.tmp")); !os.IsNotExist(err) {
+		t.Errorf("expected the partial message to be removed")
+	}
+
+	// New messages are spooled after the recovered ones
+	pushTexts(t, s, "four")
+
+	got := drain(t, s)
+	expected := []string{"two", "three", "four"}
+	if len(got) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, got)
+	}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Errorf("expected message %d to be %s, got %s", i, expected[i], got[i])
+		}
+	}
+}
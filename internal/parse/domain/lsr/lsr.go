@@ -0,0 +1,28 @@
+package lsr
+
+import (
+	"time"
+
+	"github.com/twpayne/go-geos"
+)
+
+// A single report from a Local Storm Report
+type LSR struct {
+	ID                 int        `json:"id,omitempty"`
+	CreatedAt          time.Time  `json:"created_at,omitempty"`
+	Product            string     `json:"product"`
+	WFO                string     `json:"wfo"`
+	Time               time.Time  `json:"time"`
+	Event              string     `json:"event"`
+	Magnitude          float64    `json:"magnitude"`
+	MagnitudeQualifier string     `json:"magnitude_qualifier"`
+	MagnitudeUnits     string     `json:"magnitude_units"`
+	City               string     `json:"city"`
+	County             string     `json:"county"`
+	State              string     `json:"state"`
+	Source             string     `json:"source"`
+	Remarks            string     `json:"remarks"`
+	Geom               *geos.Geom `json:"-"`
+	Corrected          bool       `json:"corrected"`
+	Summary            bool       `json:"summary"`
+}
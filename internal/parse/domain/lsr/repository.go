@@ -0,0 +1,7 @@
+package lsr
+
+import "context"
+
+type Repository interface {
+	CreateLSR(ctx context.Context, lsr *LSR) error
+}
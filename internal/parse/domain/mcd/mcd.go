@@ -0,0 +1,25 @@
+package mcd
+
+import (
+	"time"
+
+	"github.com/twpayne/go-geos"
+)
+
+// Mesoscale Discussion
+type MCD struct {
+	ID               int        `json:"id,omitempty"`
+	CreatedAt        time.Time  `json:"created_at,omitempty"`
+	Product          string     `json:"product"`
+	Number           int        `json:"number"`
+	Year             int        `json:"year"`
+	Issued           time.Time  `json:"issued"`
+	Expires          time.Time  `json:"expires"`
+	Concerning       string     `json:"concerning"`
+	Geom             *geos.Geom `json:"-"`
+	WatchProbability int        `json:"watch_probability"`
+	MostProbTornado  string     `json:"most_prob_tornado"`
+	MostProbGust     string     `json:"most_prob_gust"`
+	MostProbHail     string     `json:"most_prob_hail"`
+	Watches          []int      `json:"watches"` // IDs of the VTEC events of the watches concerned
+}
@@ -0,0 +1,7 @@
+package mcd
+
+import "context"
+
+type Repository interface {
+	CreateMCD(ctx context.Context, mcd *MCD) error
+}
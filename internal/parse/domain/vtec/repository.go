@@ -10,4 +10,11 @@ type Repository interface {
 	GetEventByID(ctx context.Context, id int) (*VTECEvent, error)
 	GetEventByVTEC(ctx context.Context, vtec awips.VTEC, year int) (*VTECEvent, error)
 	CreateEvent(ctx context.Context, event *VTECEvent) error
+	UpdateEvent(ctx context.Context, event *VTECEvent) error
+	CreateUpdate(ctx context.Context, update *VTECUpdate) error
+	GetUGCID(ctx context.Context, code string) (int, error)
+	GetUGC(ctx context.Context, event *VTECEvent, ugc int) (*VTECUGC, error)
+	CreateUGC(ctx context.Context, ugc *VTECUGC) error
+	UpdateUGC(ctx context.Context, ugc *VTECUGC) error
+	CreateTropicalThreat(ctx context.Context, threat *TropicalThreat) error
 }
@@ -0,0 +1,23 @@
+package vtec
+
+import (
+	"time"
+
+	"github.com/metdatasystem/mds-awips/pkg/awips/products"
+)
+
+// The tropical threats to a UGC from a TCV, linked to the tropical events in effect for it
+type TropicalThreat struct {
+	ID           int                  `json:"id,omitempty"`
+	CreatedAt    time.Time            `json:"created_at,omitempty"`
+	Product      string               `json:"product"`
+	WFO          string               `json:"wfo"`
+	UGC          int                  `json:"ugc"`
+	Issued       time.Time            `json:"issued"`
+	Events       []int                `json:"events"` // IDs of the VTEC events of the segment
+	Wind         string               `json:"wind"`   // Threat levels, empty if not assessed
+	StormSurge   string               `json:"storm_surge"`
+	FloodingRain string               `json:"flooding_rain"`
+	Tornado      string               `json:"tornado"`
+	Hazards      []products.TCVHazard `json:"hazards"`
+}
@@ -1 +1,13 @@
 package vtec
+
+import (
+	"errors"
+
+	"github.com/metdatasystem/mds-awips/pkg/db"
+)
+
+// Returned when a county/zone code does not exist in the database
+var ErrUGCNotFound = errors.New("ugc not found")
+
+// VTEC UGC Relation
+type VTECUGC db.VTECUGC
@@ -1 +1,6 @@
 package vtec
+
+import "github.com/metdatasystem/mds-awips/pkg/db"
+
+// VTEC Event Update
+type VTECUpdate db.VTECUpdate
@@ -1,28 +1,62 @@
 package handler
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log/slog"
 	"regexp"
-	"strings"
 	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/metdatasystem/mds-awips/internal/parse/infrastructure/db"
+	rabbit "github.com/metdatasystem/mds-awips/internal/parse/infrastructure/messaging"
 	"github.com/metdatasystem/mds-awips/pkg/awips"
 	"github.com/metdatasystem/mds-awips/pkg/logger"
 )
 
 var (
-	vtecRoute = regexp.MustCompile("(MWW|FWW|CFW|TCV|RFW|FFA|SVR|TOR|SVS|SMW|MWS|NPW|WCN|WSW|EWW|FLS)")
-	mcdRoute  = regexp.MustCompile("(SWOMCD)")
+	vtecRoute     = regexp.MustCompile("^(MWW|FWW|CFW|TCV|RFW|FFA|SVR|TOR|SVS|SMW|MWS|NPW|WCN|WSW|EWW|FLS|FLW|FFW|FFS|WOU)")
+	mcdRoute      = regexp.MustCompile("(SWOMCD)")
+	lsrRoute      = regexp.MustCompile("^LSR")
+	tropicalRoute = regexp.MustCompile("^(TCM|TCP)")
+	tcvRoute      = regexp.MustCompile("^TCV")
 )
 
 var routes = []Route{
 	// VTEC Products
 	{
-		Name:    "VTEC Handler",
-		Match:   func(product *awips.TextProduct) bool { return vtecRoute.MatchString(product.AWIPS.Product) },
-		Handler: func(handler Handler) HandlerFunc { return &vtecHandler{handler, db.NewVTECRepository(handler.db)} },
+		Name:       "VTEC Handler",
+		Match:      func(product *awips.TextProduct) bool { return vtecRoute.MatchString(product.AWIPS.Product) },
+		Handler:    func(handler Handler) HandlerFunc { return &vtecHandler{handler, db.NewVTECRepository(handler.tx)} },
+		Cumulative: true,
+	},
+	// Tropical zone threats, after the VTEC handler so the events exist
+	{
+		Name:    "TCV Handler",
+		Match:   func(product *awips.TextProduct) bool { return tcvRoute.MatchString(product.AWIPS.Product) },
+		Handler: func(handler Handler) HandlerFunc { return &tcvHandler{handler, db.NewVTECRepository(handler.tx)} },
+	},
+	// Mesoscale Discussions
+	{
+		Name:  "MCD Handler",
+		Match: func(product *awips.TextProduct) bool { return mcdRoute.MatchString(product.AWIPS.Original) },
+		Handler: func(handler Handler) HandlerFunc {
+			return &mcdHandler{handler, db.NewMCDRepository(handler.tx), db.NewVTECRepository(handler.tx)}
+		},
+	},
+	// Local Storm Reports
+	{
+		Name:    "LSR Handler",
+		Match:   func(product *awips.TextProduct) bool { return lsrRoute.MatchString(product.AWIPS.Product) },
+		Handler: func(handler Handler) HandlerFunc { return &lsrHandler{handler, db.NewLSRRepository(handler.tx)} },
+	},
+	// Tropical Cyclone Advisories
+	{
+		Name:    "Tropical Handler",
+		Match:   func(product *awips.TextProduct) bool { return tropicalRoute.MatchString(product.AWIPS.Product) },
+		Handler: func(handler Handler) HandlerFunc { return &tropicalHandler{handler} },
 	},
 }
 
@@ -30,46 +64,180 @@ type Route struct {
 	Name    string
 	Match   func(product *awips.TextProduct) bool
 	Handler func(handler Handler) HandlerFunc
+	// The route builds on what earlier products stored, such as the state of VTEC events, so a stored product is only
+	// passed to it again when everything is being rebuilt in order
+	Cumulative bool
 }
 
 type Handler struct {
+	ctx          context.Context
 	db           *pgxpool.Pool
+	tx           pgx.Tx // Everything stored for a product is done in this transaction
+	publisher    *rabbit.Publisher
+	outbox       *[]outboxMessage // Messages are only published once the transaction is committed
 	log          *logger.Logger
 	text         string
 	receivedAt   time.Time
+	metadata     Metadata
 	product      TextProduct
+	stored       *TextProduct // Set when reprocessing a product that has already been stored
+	rebuild      bool         // Set when reprocessing every product in order, so cumulative routes are handled too
 	awipsProduct *awips.TextProduct
 }
 
+// Header data provided by the source alongside the text, such as the NWWS-OI message attributes.
+// When present it is trusted over what is parsed from the text.
+type Metadata struct {
+	CCCC    string
+	TTAAII  string
+	AWIPSID string
+	Issue   time.Time
+	ID      string
+}
+
 type HandlerFunc interface {
-	Handle()
-	// Commit() error
+	// Handle the product. Returned errors are treated as transient and the product may be handled again.
+	Handle() error
 }
 
-func New(db *pgxpool.Pool, minlog int, text string, receivedAt time.Time) *Handler {
-	log := logger.New(db, slog.Level(minlog))
+// Creates a new handler for a product. The publisher may be nil if results should not be published.
+func New(ctx context.Context, db *pgxpool.Pool, publisher *rabbit.Publisher, minlog int, text string, receivedAt time.Time, metadata Metadata) *Handler {
+	log := logger.New(slog.Level(minlog))
+	log.Text = text
 
 	return &Handler{
+		ctx:        ctx,
 		db:         db,
+		publisher:  publisher,
+		outbox:     &[]outboxMessage{},
 		log:        &log,
 		text:       text,
 		receivedAt: receivedAt,
+		metadata:   metadata,
+	}
+}
+
+// Creates a new handler for a product that has already been stored, so it is passed to the routes without being stored again.
+// Cumulative routes are skipped unless rebuild is set, as they can only be rebuilt by reprocessing every product in order.
+func NewStored(ctx context.Context, db *pgxpool.Pool, publisher *rabbit.Publisher, minlog int, product TextProduct, rebuild bool) *Handler {
+	receivedAt := time.Time{}
+	if product.ReceivedAt != nil {
+		receivedAt = *product.ReceivedAt
+	}
+
+	handler := New(ctx, db, publisher, minlog, product.Data, receivedAt, Metadata{})
+	handler.stored = &product
+	handler.rebuild = rebuild
+	return handler
+}
+
+// Handle the product in a single transaction. If anything fails to be stored the whole product is rolled back.
+// Products that cannot be parsed are logged and dropped, any returned error is from storing the product and is worth retrying.
+func (handler *Handler) Handle() error {
+	tx, err := handler.db.Begin(handler.ctx)
+	if err != nil {
+		return err
+	}
+	// Does nothing once the transaction has been committed
+	defer tx.Rollback(handler.ctx)
+
+	handler.tx = tx
+
+	err = handler.handle()
+	if err != nil {
+		return err
+	}
+
+	// The logs of a stored product were saved when it was first handled
+	if handler.stored == nil {
+		err = handler.log.Commit(handler.ctx, tx)
+		if err != nil {
+			return err
+		}
 	}
+
+	err = tx.Commit(handler.ctx)
+	if err != nil {
+		return err
+	}
+
+	handler.flush()
+
+	return nil
+}
+
+// Parse the product and pass it to any matching routes.
+func (handler *Handler) handle() error {
+	log := handler.log
+
+	parsed, _, err := handler.Parse()
+	if err != nil {
+		// Already logged, there is nothing to store
+		return nil
+	}
+	handler.awipsProduct = parsed
+
+	committedProduct := false
+
+	// Process the product matching it to any routes
+	for _, route := range routes {
+		if route.Match(handler.awipsProduct) && !handler.skip(route) {
+			if !committedProduct {
+				product := handler.stored
+				if product == nil {
+					pHandler := productHandler{*handler}
+					product, err = pHandler.Handle()
+					if err != nil {
+						log.Error("failed to handle product", "error", err)
+						return err
+					}
+				} else {
+					// The routes derive everything from the product again
+					err = db.NewProductRepository(handler.tx).DeleteDerived(handler.ctx, product.ProductID)
+					if err != nil {
+						log.Error("failed to remove what was derived from the product", "error", err)
+						return err
+					}
+				}
+				handler.product = *product
+				log.Product = product.ProductID
+				committedProduct = true
+				handler.publishProduct()
+			}
+			h := route.Handler(*handler)
+			err := h.Handle()
+			if err != nil {
+				log.Error("failed to handle route", "error", err, "handler", route.Name)
+				return fmt.Errorf("%s: %w", route.Name, err)
+			}
+		}
+	}
+
+	return nil
 }
 
-func (handler *Handler) Handle() {
+/*
+Parse the product, applying the source's header. Returns an error if the product cannot be stored, such as when it has
+no AWIPS header. Segments are kept without whatever part of them failed to parse and those errors are returned too.
+Everything is also logged.
+*/
+func (handler *Handler) Parse() (*awips.TextProduct, []error, error) {
 	log := handler.log
 	text := handler.text
 
 	// Get the WMO header
-	wmo, err := awips.ParseWMO(text)
+	wmo, err := handler.metadata.applyWMO(awips.ParseWMO(text))
 	if err != nil {
-		log.Error(err.Error())
-		return
+		log.Fail(err.Error())
+		return nil, nil, err
 	}
+	log.WMO = wmo.Original
 
 	// Get the AWIPS header
 	awipsHeader, err := awips.ParseAWIPS(text)
+	if sourceHeader, e := handler.metadata.awips(); e == nil {
+		awipsHeader, err = sourceHeader, nil
+	}
 	if err != nil {
 		log.Debug(err.Error())
 	}
@@ -77,89 +245,38 @@ func (handler *Handler) Handle() {
 	// No point continuing if there is no AWIPS header
 	if awipsHeader.Original == "" {
 		log.Info("AWIPS header not found. Product will not be stored.")
-		return
+		return nil, nil, errors.New("AWIPS header not found")
 	} else {
 		log.With("awips", awipsHeader.Original)
+		log.AWIPS = awipsHeader.Original
 	}
 
 	// Find the issue time
 	issued, err := awips.GetIssuedTime(text)
 	if err != nil {
-		log.Error(err.Error())
-		return
+		log.Fail(err.Error())
+		return nil, nil, err
+	}
+	if issued.IsZero() && !handler.metadata.Issue.IsZero() {
+		log.Info("Product does not contain issue date. Defaulting to the source issue time")
+		issued = handler.metadata.Issue
 	}
 	if issued.IsZero() {
 		log.Info("Product does not contain issue date. Defaulting to now (UTC)")
 		issued = time.Now().UTC()
 	}
 
-	// Segment the product
-	splits := strings.Split(text, "$$")
-
-	segments := []awips.TextProductSegment{}
-
-	for _, segment := range splits {
-		segment = strings.TrimSpace(segment)
-
-		// Assume the segment is the end of the product if it is shorter than 10 characters
-		if len(segment) < 20 {
-			continue
-		}
-
-		ugc, err := awips.ParseUGC(segment)
-		if err != nil {
-			log.Error(err.Error())
-			continue
-		}
-		expires := time.Now().UTC()
-		if ugc != nil {
-			expires = time.Date(issued.Year(), issued.Month(), ugc.Expires.Day(), ugc.Expires.Hour(), ugc.Expires.Minute(), 0, 0, time.UTC)
-			if ugc.Expires.Day() > wmo.Issued.Day() && ugc.Expires.Day() == 1 {
-				expires = expires.AddDate(0, 1, 0)
-			}
-			ugc.Merge(issued)
-		}
-
-		// Find any VTECs that the segment may have
-		vtec, e := awips.ParseVTEC(segment)
-		if len(e) != 0 {
-			for _, er := range e {
-				log.Error(er.Error())
-			}
-			continue
-		}
-
-		latlon, err := awips.ParseLatLon(segment)
-		if err != nil {
-			log.Error(err.Error())
+	segments, errs := awips.GetSegments(text, issued, wmo)
+	for _, err := range errs {
+		// The segment is still handled without the H-VTEC or TML
+		if awips.IsParseError(err, awips.ComponentHVTEC) || awips.IsParseError(err, awips.ComponentTML) {
+			log.Warn(err.Error())
 			continue
 		}
-
-		tags, e := awips.ParseTags(segment)
-		if len(e) != 0 {
-			for _, er := range e {
-				log.Error(er.Error())
-			}
-		}
-
-		tml, err := awips.ParseTML(segment, issued)
-		if err != nil {
-			log.Warn("failed to parse TML: " + err.Error())
-		}
-
-		segments = append(segments, awips.TextProductSegment{
-			Text:    segment,
-			VTEC:    vtec,
-			UGC:     ugc,
-			Expires: expires,
-			LatLon:  latlon,
-			Tags:    tags,
-			TML:     tml,
-		})
-
+		log.Error(err.Error())
 	}
 
-	handler.awipsProduct = &awips.TextProduct{
+	product := &awips.TextProduct{
 		Text:     handler.text,
 		WMO:      wmo,
 		AWIPS:    awipsHeader,
@@ -169,29 +286,55 @@ func (handler *Handler) Handle() {
 		Segments: segments,
 	}
 
-	committedProduct := false
+	return product, errs, nil
+}
 
-	// Process the product matching it to any routes
-	for _, route := range routes {
-		if route.Match(handler.awipsProduct) {
-			if !committedProduct {
-				pHandler := productHandler{*handler}
-				product, err := pHandler.Handle()
-				if err != nil {
-					log.Error("failed to handle product", "error", err)
-					continue
-				}
-				handler.product = *product
-			}
-			h := route.Handler(*handler)
-			h.Handle()
-			// if err != nil {
-			// 	handler.log.Error("failed to save log", "error", err, "handler", route.Name)
-			// }
-		}
+// Stored products are not passed to cumulative routes unless everything is being rebuilt
+func (handler *Handler) skip(route Route) bool {
+	return handler.stored != nil && route.Cumulative && !handler.rebuild
+}
+
+// Overrides the parsed WMO header with the source's. If the header could not be parsed, it is built from the source entirely.
+func (metadata Metadata) applyWMO(wmo awips.WMO, err error) (awips.WMO, error) {
+	if metadata.TTAAII == "" || metadata.CCCC == "" {
+		return wmo, err
+	}
+
+	wmo.Datatype = metadata.TTAAII
+	wmo.Office = metadata.CCCC
+	if !metadata.Issue.IsZero() {
+		// The WMO header only has the day and time
+		wmo.Issued = time.Date(0, 1, metadata.Issue.Day(), metadata.Issue.Hour(), metadata.Issue.Minute(), 0, 0, time.UTC)
 	}
+	if err != nil {
+		wmo.Original = fmt.Sprintf("%s %s %s", wmo.Datatype, wmo.Office, wmo.Issued.Format("021504"))
+	}
+
+	return wmo, nil
+}
+
+// Builds the AWIPS header from the source's AWIPS identifier
+func (metadata Metadata) awips() (awips.AWIPS, error) {
+	id := metadata.AWIPSID
+	if len(id) < 4 {
+		return awips.AWIPS{}, fmt.Errorf("AWIPS identifier %s is too short", id)
+	}
+
+	return awips.AWIPS{
+		Original: id,
+		Product:  id[:3],
+		WFO:      id[3:],
+	}, nil
+}
+
+// Save the logs outside of a transaction with the reason the product could not be stored, such as after it has failed
+// every retry.
+func (handler *Handler) SaveFailure(reason error) error {
+	handler.log.Fail("product could not be stored", "error", reason)
+	return handler.log.Commit(handler.ctx, handler.db)
 }
 
-func (handler *Handler) SaveLog() error {
-	return handler.log.Commit()
+// The errors logged while handling the product
+func (handler *Handler) Errors() []string {
+	return handler.log.Errors()
 }
@@ -0,0 +1,222 @@
+package handler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/metdatasystem/mds-awips/pkg/awips"
+)
+
+func TestMetadataApplyWMO(t *testing.T) {
+	issue := time.Date(2025, 6, 26, 23, 36, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		text      string
+		metadata  Metadata
+		expected  awips.WMO
+		expectErr bool
+	}{
+		{
+			name:     "no metadata",
+			text:     "WFUS51 KOKX 262336\nTOROKX\n",
+			metadata: Metadata{},
+			expected: awips.WMO{
+				Original: "WFUS51 KOKX 262336",
+				Datatype: "WFUS51",
+				Office:   "KOKX",
+				Issued:   time.Date(0, 1, 26, 23, 36, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:     "metadata overrides the header",
+			text:     "WFUS51 KOKX 262336 CCA\nTOROKX\n",
+			metadata: Metadata{TTAAII: "WFUS52", CCCC: "KOKY", Issue: issue.Add(time.Minute)},
+			expected: awips.WMO{
+				Original: "WFUS51 KOKX 262336 CCA",
+				Datatype: "WFUS52",
+				Office:   "KOKY",
+				Issued:   time.Date(0, 1, 26, 23, 37, 0, 0, time.UTC),
+				BBB:      "CCA",
+			},
+		},
+		{
+			name:     "metadata without an issue time",
+			text:     "WFUS51 KOKX 262336\nTOROKX\n",
+			metadata: Metadata{TTAAII: "WFUS51", CCCC: "KOKX"},
+			expected: awips.WMO{
+				Original: "WFUS51 KOKX 262336",
+				Datatype: "WFUS51",
+				Office:   "KOKX",
+				Issued:   time.Date(0, 1, 26, 23, 36, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:     "bad header rebuilt from the metadata",
+			text:     "WFUS51 KOKX 2623\nTOROKX\n",
+			metadata: Metadata{TTAAII: "WFUS51", CCCC: "KOKX", Issue: issue},
+			expected: awips.WMO{
+				Original: "WFUS51 KOKX 262336",
+				Datatype: "WFUS51",
+				Office:   "KOKX",
+				Issued:   time.Date(0, 1, 26, 23, 36, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:      "bad header without metadata",
+			text:      "WFUS51 KOKX 2623\nTOROKX\n",
+			metadata:  Metadata{},
+			expectErr: true,
+		},
+		{
+			name:      "bad header with missing attributes",
+			text:      "WFUS51 KOKX 2623\nTOROKX\n",
+			metadata:  Metadata{TTAAII: "WFUS51", Issue: issue},
+			expectErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			wmo, err := test.metadata.applyWMO(awips.ParseWMO(test.text))
+			if test.expectErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %+v", wmo)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if wmo != test.expected {
+				t.Errorf("expected %+v, got %+v", test.expected, wmo)
+			}
+		})
+	}
+}
+
+func TestMetadataAWIPS(t *testing.T) {
+	tests := []struct {
+		name      string
+		id        string
+		expected  awips.AWIPS
+		expectErr bool
+	}{
+		{"identifier", "TOROKX", awips.AWIPS{Original: "TOROKX", Product: "TOR", WFO: "OKX"}, false},
+		{"short office", "SPSAK", awips.AWIPS{Original: "SPSAK", Product: "SPS", WFO: "AK"}, false},
+		{"too short", "TOR", awips.AWIPS{}, true},
+		{"missing", "", awips.AWIPS{}, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			header, err := Metadata{AWIPSID: test.id}.awips()
+			if test.expectErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %+v", header)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if header != test.expected {
+				t.Errorf("expected %+v, got %+v", test.expected, header)
+			}
+		})
+	}
+}
+
+func TestHandlerSkip(t *testing.T) {
+	cumulative := Route{Name: "cumulative", Cumulative: true}
+	other := Route{Name: "other"}
+
+	tests := []struct {
+		name     string
+		handler  *Handler
+		expected map[string]bool
+	}{
+		{"new product", New(context.Background(), nil, nil, 0, "", time.Time{}, Metadata{}), map[string]bool{"cumulative": false, "other": false}},
+		{"stored product", NewStored(context.Background(), nil, nil, 0, TextProduct{}, false), map[string]bool{"cumulative": true, "other": false}},
+		{"rebuilding", NewStored(context.Background(), nil, nil, 0, TextProduct{}, true), map[string]bool{"cumulative": false, "other": false}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for _, route := range []Route{cumulative, other} {
+				if skipped := test.handler.skip(route); skipped != test.expected[route.Name] {
+					t.Errorf("expected the %s route to be skipped: %t, got %t", route.Name, test.expected[route.Name], skipped)
+				}
+			}
+		})
+	}
+
+	// Only the VTEC handler builds on earlier products
+	for _, route := range routes {
+		if route.Cumulative != (route.Name == "VTEC Handler") {
+			t.Errorf("unexpected cumulative %t for %s", route.Cumulative, route.Name)
+		}
+	}
+}
+
+func TestHandlerParse(t *testing.T) {
+	text := `WWUS52 KRAH 262350
+SVSRAH
+
+Severe Weather Statement
+National Weather Service Raleigh NC
+750 PM EDT Thu Jun 26 2025
+
+NCC063-270015-
+/O.CON.KRAH.SV.W.0175.000000T0000Z-250627T0015Z/
+
+LAT...LON 3581 7875 3573
+
+$$
+
+NCC135-273015-
+/O.CON.KRAH.SV.W.0176.000000T0000Z-250627T0015Z/
+
+TIME...MOT...LOC 2350Z XXXDEG 21KT 3583 7896
+
+$$
+`
+
+	h := New(context.Background(), nil, nil, 100, text, time.Now(), Metadata{})
+	product, errs, err := h.Parse()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if product.AWIPS.Original != "SVSRAH" || product.Office != "KRAH" {
+		t.Errorf("unexpected headers %+v %+v", product.AWIPS, product.WMO)
+	}
+	if !product.Issued.Equal(time.Date(2025, 6, 26, 23, 50, 0, 0, time.UTC)) {
+		t.Errorf("unexpected issue time %v", product.Issued)
+	}
+	if len(product.Segments) != 2 {
+		t.Fatalf("expected both segments to be kept, got %d", len(product.Segments))
+	}
+
+	components := []string{awips.ComponentLatLon, awips.ComponentUGC, awips.ComponentTML}
+	if len(errs) != len(components) {
+		t.Fatalf("expected %d errors, got %v", len(components), errs)
+	}
+	for i, component := range components {
+		if !awips.IsParseError(errs[i], component) {
+			t.Errorf("expected error %d to be a %s error, got %v", i, component, errs[i])
+		}
+	}
+
+	// The TML is only a warning
+	if len(h.Errors()) != 2 {
+		t.Errorf("expected 2 errors to be logged, got %v", h.Errors())
+	}
+
+	// Nothing can be stored without an AWIPS header
+	_, _, err = New(context.Background(), nil, nil, 100, "WWUS52 KRAH 262350\n\n", time.Now(), Metadata{}).Parse()
+	if err == nil {
+		t.Error("expected an error for a product without an AWIPS header")
+	}
+}
@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/metdatasystem/mds-awips/internal/parse/domain/lsr"
+	"github.com/metdatasystem/mds-awips/internal/parse/util"
+	"github.com/metdatasystem/mds-awips/pkg/awips/products"
+)
+
+type lsrHandler struct {
+	Handler
+	repo lsr.Repository
+}
+
+func (handler *lsrHandler) Handle() error {
+	product := handler.product
+	log := handler.log
+
+	log.With("product", product.ProductID)
+
+	reports, err := products.ParseLSR(handler.text)
+	if err != nil {
+		log.Error("failed to parse LSR", "error", err)
+		return nil
+	}
+
+	for _, report := range reports {
+		l := &lsr.LSR{
+			Product:            product.ProductID,
+			WFO:                handler.awipsProduct.Office,
+			Time:               report.Time.UTC(),
+			Event:              report.Event,
+			Magnitude:          report.Magnitude,
+			MagnitudeQualifier: report.MagnitudeQualifier,
+			MagnitudeUnits:     report.MagnitudeUnits,
+			City:               report.City,
+			County:             report.County,
+			State:              report.State,
+			Source:             report.Source,
+			Remarks:            report.Remarks,
+			Geom:               util.PointFromAwips(report.Point),
+			Corrected:          report.Corrected,
+			Summary:            report.Summary,
+		}
+
+		ctx, cancel := context.WithTimeout(handler.ctx, 10*time.Second)
+		err = handler.repo.CreateLSR(ctx, l)
+		cancel()
+		if err != nil {
+			log.Error("failed to create LSR", "error", err)
+			return err
+		}
+
+		handler.publish(fmt.Sprintf("lsr.%s", handler.awipsProduct.Office), LSRMessage{
+			ID:      l.ID,
+			Product: product.ProductID,
+			WFO:     l.WFO,
+			LSR:     report,
+		})
+	}
+
+	return nil
+}
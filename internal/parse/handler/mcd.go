@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/metdatasystem/mds-awips/internal/parse/domain/mcd"
+	"github.com/metdatasystem/mds-awips/internal/parse/domain/vtec"
+	"github.com/metdatasystem/mds-awips/internal/parse/util"
+	"github.com/metdatasystem/mds-awips/pkg/awips"
+	"github.com/metdatasystem/mds-awips/pkg/awips/products"
+)
+
+type mcdHandler struct {
+	Handler
+	repo     mcd.Repository
+	vtecRepo vtec.Repository
+}
+
+func (handler *mcdHandler) Handle() error {
+	product := handler.product
+	log := handler.log
+
+	log.With("product", product.ProductID)
+
+	parsed, err := products.ParseMCD(handler.text)
+	if err != nil {
+		log.Error("failed to parse MCD", "error", err)
+		return nil
+	}
+
+	// The MCD only has the day and time of the valid period
+	parsed.Issued = util.MergeDayTime(parsed.Issued, *product.Issued)
+	parsed.Expires = util.MergeDayTime(parsed.Expires, *product.Issued)
+
+	m := &mcd.MCD{
+		Product:          product.ProductID,
+		Number:           parsed.Number,
+		Year:             parsed.Issued.Year(),
+		Issued:           parsed.Issued,
+		Expires:          parsed.Expires,
+		Concerning:       parsed.Concerning,
+		Geom:             util.PolygonFromAwips(parsed.Polygon),
+		WatchProbability: parsed.WatchProbability,
+		MostProbTornado:  parsed.MostProbTornado,
+		MostProbGust:     parsed.MostProbGust,
+		MostProbHail:     parsed.MostProbHail,
+		Watches:          []int{},
+	}
+
+	// Link the watches that the MCD is concerning, the KWNS watch events are created from the WOU
+	for _, watch := range parsed.Watches {
+		ctx, cancel := context.WithTimeout(handler.ctx, 10*time.Second)
+		event, err := handler.vtecRepo.GetEventByVTEC(ctx, awips.VTEC{
+			WFO:          "KWNS",
+			Phenomena:    watch.Phenomena,
+			Significance: "A",
+			EventNumber:  watch.Number,
+		}, m.Year)
+		cancel()
+		if err != nil {
+			log.Error("failed to find MCD watch", "error", err, "watch", watch.Number)
+			return err
+		}
+		if event == nil {
+			log.Warn(fmt.Sprintf("MCD %d references watch %d which has not been stored", m.Number, watch.Number))
+			continue
+		}
+		m.Watches = append(m.Watches, event.ID)
+	}
+
+	ctx, cancel := context.WithTimeout(handler.ctx, 10*time.Second)
+	defer cancel()
+
+	err = handler.repo.CreateMCD(ctx, m)
+	if err != nil {
+		log.Error("failed to create MCD", "error", err)
+		return err
+	}
+
+	handler.publish(fmt.Sprintf("mcd.%s", handler.awipsProduct.Office), MCDMessage{
+		ID:      m.ID,
+		Product: product.ProductID,
+		Watches: m.Watches,
+		MCD:     parsed,
+	})
+
+	return nil
+}
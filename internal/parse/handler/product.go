@@ -1,7 +1,6 @@
 package handler
 
 import (
-	"context"
 	"errors"
 	"fmt"
 	"regexp"
@@ -35,7 +34,7 @@ func (handler *productHandler) Handle() (*TextProduct, error) {
 		BBB:        product.WMO.BBB,
 	}
 
-	rows, err := handler.db.Query(context.Background(), `
+	rows, err := handler.tx.Query(handler.ctx, `
 	INSERT INTO awips.products (product_id, received_at, issued, source, data, wmo, awips, bbb) VALUES
 	($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id, created_at;
 	`, id, handler.receivedAt, product.Issued, product.AWIPS.WFO, product.Text, product.WMO.Datatype, product.AWIPS.Original, product.WMO.BBB)
@@ -52,7 +51,7 @@ func (handler *productHandler) Handle() (*TextProduct, error) {
 		return nil, rows.Err()
 	}
 
-	return nil, errors.New("no rows returned when creating new text product: " + rows.Err().Error())
+	return nil, errors.New("no rows returned when creating new text product")
 }
 
 func (product *TextProduct) isCorrection() bool {
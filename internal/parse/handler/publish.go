@@ -0,0 +1,159 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/metdatasystem/mds-awips/internal/parse/domain/vtec"
+	"github.com/metdatasystem/mds-awips/pkg/awips"
+	"github.com/metdatasystem/mds-awips/pkg/awips/products"
+)
+
+/*
+Messages published to the topic exchange for downstream services.
+
+Routing keys:
+  - product.<awips>.<wfo> for every stored product, e.g. product.TOR.KRAH
+  - vtec.<phenomena>.<significance>.<action>.<wfo> for every VTEC event change, e.g. vtec.TO.W.NEW.KRAH
+  - mcd.<wfo> for every stored mesoscale discussion, e.g. mcd.KWNS
+  - lsr.<wfo> for every stored storm report, e.g. lsr.KRAH
+  - tropical.<awips>.<storm> for every tropical cyclone advisory, e.g. tropical.TCM.AL052025
+*/
+
+// A change to a VTEC event
+type VTECMessage struct {
+	EventID      int                   `json:"event_id"`
+	Product      string                `json:"product"`
+	Issued       time.Time             `json:"issued"`
+	Starts       time.Time             `json:"starts"`
+	Expires      time.Time             `json:"expires"`
+	Ends         time.Time             `json:"ends"`
+	Action       string                `json:"action"`
+	Class        string                `json:"class"`
+	WFO          string                `json:"wfo"`
+	Phenomena    string                `json:"phenomena"`
+	Significance string                `json:"significance"`
+	EventNumber  int                   `json:"event_number"`
+	Year         int                   `json:"year"`
+	Title        string                `json:"title"`
+	IsEmergency  bool                  `json:"is_emergency"`
+	IsPDS        bool                  `json:"is_pds"`
+	UGC          []string              `json:"ugc"`
+	Polygon      *awips.PolygonFeature `json:"polygon"`
+	TML          *awips.TML            `json:"tml"`
+	HVTEC        *awips.HVTEC          `json:"hvtec"`
+	Tags         awips.ImpactTags      `json:"tags"`
+}
+
+// A stored mesoscale discussion
+type MCDMessage struct {
+	ID      int           `json:"id"`
+	Product string        `json:"product"`
+	Watches []int         `json:"watches"` // IDs of the VTEC events of the watches concerned
+	MCD     *products.MCD `json:"mcd"`
+}
+
+// A stored storm report
+type LSRMessage struct {
+	ID      int          `json:"id"`
+	Product string       `json:"product"`
+	WFO     string       `json:"wfo"`
+	LSR     products.LSR `json:"lsr"`
+}
+
+// A decoded tropical cyclone advisory, only one of TCM or TCP is set
+type TropicalMessage struct {
+	Product string        `json:"product"`
+	StormID string        `json:"storm_id"`
+	TCM     *products.TCM `json:"tcm,omitempty"`
+	TCP     *products.TCP `json:"tcp,omitempty"`
+}
+
+type outboxMessage struct {
+	routingKey string
+	message    any
+}
+
+// Queue a message to be published once the product has been committed
+func (handler *Handler) publish(routingKey string, message any) {
+	*handler.outbox = append(*handler.outbox, outboxMessage{routingKey, message})
+}
+
+// How many times a message is published before it is given up on
+const publishAttempts = 3
+
+// Publish the queued messages if the handler has a publisher. The product has already been committed along with its
+// logs, so a message that still fails after retrying is only logged to stdout.
+func (handler *Handler) flush() {
+	outbox := *handler.outbox
+	*handler.outbox = nil
+
+	if handler.publisher == nil {
+		return
+	}
+
+	for _, m := range outbox {
+		err := handler.publishWithRetry(m)
+		if err != nil {
+			slog.Error("failed to publish message", "error", err, "key", m.routingKey, "product", handler.product.ProductID)
+		}
+	}
+}
+
+func (handler *Handler) publishWithRetry(m outboxMessage) error {
+	var err error
+	for attempt := 1; attempt <= publishAttempts; attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		err = handler.publisher.Publish(ctx, m.routingKey, m.message)
+		cancel()
+		if err == nil {
+			return nil
+		}
+		if attempt < publishAttempts {
+			slog.Warn("failed to publish message, retrying", "error", err, "key", m.routingKey, "attempt", attempt)
+			time.Sleep(time.Duration(attempt) * time.Second)
+		}
+	}
+	return err
+}
+
+func (handler *Handler) publishProduct() {
+	product := handler.awipsProduct
+	handler.publish(fmt.Sprintf("product.%s.%s", product.AWIPS.Product, product.Office), handler.product)
+}
+
+func (handler *vtecHandler) publishVTEC(v awips.VTEC, event *vtec.VTECEvent, segment awips.TextProductSegment) {
+	message := VTECMessage{
+		EventID:      event.ID,
+		Product:      handler.product.ProductID,
+		Issued:       *handler.product.Issued,
+		Starts:       event.Starts,
+		Expires:      event.Expires,
+		Ends:         event.Ends,
+		Action:       v.Action,
+		Class:        v.Class,
+		WFO:          v.WFO,
+		Phenomena:    v.Phenomena,
+		Significance: v.Significance,
+		EventNumber:  v.EventNumber,
+		Year:         event.Year,
+		Title:        event.Title,
+		IsEmergency:  event.IsEmergency,
+		IsPDS:        event.IsPDS,
+		UGC:          []string{},
+		TML:          segment.TML,
+		HVTEC:        segment.HVTEC,
+		Tags:         segment.Tags,
+	}
+
+	if segment.UGC != nil {
+		message.UGC = segment.UGC.Codes()
+	}
+	if segment.LatLon != nil {
+		message.Polygon = segment.LatLon.Polygon
+	}
+
+	handler.publish(fmt.Sprintf("vtec.%s.%s.%s.%s", v.Phenomena, v.Significance, v.Action, v.WFO), message)
+}
@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/metdatasystem/mds-awips/internal/parse/domain/vtec"
+	"github.com/metdatasystem/mds-awips/pkg/awips/products"
+)
+
+// Stores the threats of each zone in a TCV. The VTEC handler has already stored the events of the product.
+type tcvHandler struct {
+	Handler
+	repo vtec.Repository
+}
+
+func (handler *tcvHandler) Handle() error {
+	product := handler.product
+	log := handler.log
+
+	log.With("product", product.ProductID)
+
+	parsed, err := products.ParseTCV(handler.text)
+	if err != nil {
+		log.Error("failed to parse TCV", "error", err)
+		return nil
+	}
+
+	for _, zone := range parsed.Zones {
+		threat := vtec.TropicalThreat{
+			Product: product.ProductID,
+			WFO:     handler.awipsProduct.Office,
+			Issued:  *product.Issued,
+			Events:  []int{},
+			Hazards: zone.Hazards,
+		}
+
+		for _, hazard := range zone.Hazards {
+			switch hazard.Hazard {
+			case "WIND":
+				threat.Wind = hazard.Level
+			case "STORM SURGE":
+				threat.StormSurge = hazard.Level
+			case "FLOODING RAIN":
+				threat.FloodingRain = hazard.Level
+			case "TORNADO":
+				threat.Tornado = hazard.Level
+			}
+		}
+
+		// Link the events of the segment
+		for _, v := range zone.VTEC {
+			if v.Class == "T" || v.Action == "ROU" {
+				continue
+			}
+
+			year := product.Issued.Year()
+			if v.Start != nil {
+				year = v.Start.Year()
+			}
+
+			ctx, cancel := context.WithTimeout(handler.ctx, 10*time.Second)
+			event, err := handler.repo.GetEventByVTEC(ctx, v, year)
+			cancel()
+			if err != nil {
+				log.Error("failed to find TCV event", "error", err, "vtec", v.Original)
+				return err
+			}
+			if event == nil {
+				log.Warn("TCV event has not been stored", "vtec", v.Original)
+				continue
+			}
+			threat.Events = append(threat.Events, event.ID)
+		}
+
+		for _, code := range zone.UGC {
+			ctx, cancel := context.WithTimeout(handler.ctx, 10*time.Second)
+			id, err := handler.repo.GetUGCID(ctx, code)
+			cancel()
+			if errors.Is(err, vtec.ErrUGCNotFound) {
+				log.Warn("TCV UGC is not known", "ugc", code)
+				continue
+			}
+			if err != nil {
+				log.Error("failed to get TCV UGC", "error", err, "ugc", code)
+				return err
+			}
+
+			t := threat
+			t.UGC = id
+
+			ctx, cancel = context.WithTimeout(handler.ctx, 10*time.Second)
+			err = handler.repo.CreateTropicalThreat(ctx, &t)
+			cancel()
+			if err != nil {
+				log.Error("failed to create tropical threat", "error", err, "ugc", code)
+				return err
+			}
+		}
+	}
+
+	return nil
+}
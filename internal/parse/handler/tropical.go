@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"fmt"
+
+	"github.com/metdatasystem/mds-awips/internal/parse/util"
+	"github.com/metdatasystem/mds-awips/pkg/awips/products"
+)
+
+type tropicalHandler struct {
+	Handler
+}
+
+// Publishes the decoded forecast/advisory or public advisory. Nothing is stored, the product itself already has been.
+func (handler *tropicalHandler) Handle() error {
+	product := handler.product
+	log := handler.log
+
+	log.With("product", product.ProductID)
+
+	message := TropicalMessage{
+		Product: product.ProductID,
+	}
+
+	switch handler.awipsProduct.AWIPS.Product {
+	case "TCM":
+		parsed, err := products.ParseTCM(handler.text)
+		if err != nil {
+			log.Error("failed to parse TCM", "error", err)
+			return nil
+		}
+
+		// The position and forecasts only have the day and time
+		parsed.Time = util.MergeDayTime(parsed.Time, *product.Issued)
+		for i := range parsed.Forecast {
+			parsed.Forecast[i].Time = util.MergeDayTime(parsed.Forecast[i].Time, *product.Issued)
+		}
+
+		message.StormID = parsed.StormID
+		message.TCM = parsed
+	case "TCP":
+		parsed, err := products.ParseTCP(handler.text)
+		if err != nil {
+			log.Error("failed to parse TCP", "error", err)
+			return nil
+		}
+
+		message.StormID = parsed.StormID
+		message.TCP = parsed
+	default:
+		return nil
+	}
+
+	handler.publish(fmt.Sprintf("tropical.%s.%s", handler.awipsProduct.AWIPS.Product, message.StormID), message)
+
+	return nil
+}
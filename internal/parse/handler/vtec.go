@@ -2,6 +2,7 @@ package handler
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -16,7 +17,7 @@ type vtecHandler struct {
 	repo vtec.Repository
 }
 
-func (handler *vtecHandler) Handle() {
+func (handler *vtecHandler) Handle() error {
 	awipsProduct := handler.awipsProduct
 	product := handler.product
 	log := handler.log
@@ -34,22 +35,65 @@ func (handler *vtecHandler) Handle() {
 		}
 
 		// Go through each VTEC in the segment and process it
-		for _, vtec := range segment.VTEC {
+		for _, v := range segment.VTEC {
 			// Skip test and routine products
-			if vtec.Class == "T" || vtec.Action == "ROU" {
+			if v.Class == "T" || v.Action == "ROU" {
 				continue
 			}
 
 			// Find or create the VTEC event
-			event, err := handler.getOrCreateVTECEvent(vtec, product, segment)
+			event, err := handler.getOrCreateVTECEvent(v, product, segment)
 			if err != nil {
-				log.Error("failed to get or create VTEC event", "error", err, "vtec", vtec.Original)
-				continue
+				log.Error("failed to get or create VTEC event", "error", err, "vtec", v.Original)
+				return err
+			}
+
+			handler.updateTimes(v, event, segment)
+
+			// Keep the event in line with the latest segment
+			event.Title = v.Title(segment.IsEmergency())
+			event.IsEmergency = segment.IsEmergency()
+			event.IsPDS = segment.IsPDS()
+
+			ctx, cancel := context.WithTimeout(handler.ctx, 10*time.Second)
+			err = handler.repo.UpdateEvent(ctx, event)
+			cancel()
+			if err != nil {
+				log.Error("failed to update VTEC event", "error", err, "vtec", v.Original)
+				return err
+			}
+
+			// Record this action against the event's history
+			update := handler.buildUpdate(v, event, segment)
+
+			ctx, cancel = context.WithTimeout(handler.ctx, 10*time.Second)
+			err = handler.repo.CreateUpdate(ctx, update)
+			cancel()
+			if err != nil {
+				log.Error("failed to create VTEC update", "error", err, "vtec", v.Original)
+				return err
+			}
+
+			// Update the state of each county/zone referenced by the segment
+			if segment.UGC != nil {
+				for _, code := range segment.UGC.Codes() {
+					err := handler.updateUGC(v, event, update, segment, code)
+					if errors.Is(err, vtec.ErrUGCNotFound) {
+						log.Warn("VTEC UGC is not known", "vtec", v.Original, "ugc", code)
+						continue
+					}
+					if err != nil {
+						log.Error("failed to update VTEC UGC", "error", err, "vtec", v.Original, "ugc", code)
+						return err
+					}
+				}
 			}
 
-			handler.updateTimes(vtec, event, segment)
+			handler.publishVTEC(v, event, segment)
 		}
 	}
+
+	return nil
 }
 
 func (handler *vtecHandler) SaveLog() error {
@@ -66,7 +110,7 @@ func (handler *vtecHandler) getOrCreateVTECEvent(v awips.VTEC, product TextProdu
 		year = product.Issued.Year()
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(handler.ctx, 10*time.Second)
 	defer cancel()
 
 	event, err := handler.repo.GetEventByVTEC(ctx, v, year)
@@ -74,7 +118,7 @@ func (handler *vtecHandler) getOrCreateVTECEvent(v awips.VTEC, product TextProdu
 		return nil, err
 	}
 
-	if event != nil {
+	if event == nil {
 		// The database needs a start and end time but VTECs may not have one.
 		if v.Start == nil {
 			// Use the issue time for the start time
@@ -96,7 +140,7 @@ func (handler *vtecHandler) getOrCreateVTECEvent(v awips.VTEC, product TextProdu
 		event = &vtec.VTECEvent{
 			Issued:       *product.Issued,
 			Starts:       *v.Start,
-			Expires:      segment.UGC.Expires,
+			Expires:      segment.Expires,
 			Ends:         *v.End,
 			EndInitial:   *v.End,
 			Class:        v.Class,
@@ -111,15 +155,13 @@ func (handler *vtecHandler) getOrCreateVTECEvent(v awips.VTEC, product TextProdu
 			PolygonStart: polygon,
 		}
 
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		ctx, cancel := context.WithTimeout(handler.ctx, 10*time.Second)
 		defer cancel()
 
 		// Create the event in the database
 		err = handler.repo.CreateEvent(ctx, event)
 	}
 
-	err := 
-
 	return event, err
 }
 
@@ -130,7 +172,7 @@ func (handler *vtecHandler) updateTimes(vtec awips.VTEC, event *vtec.VTECEvent,
 	// The product expires at the UGC expiry time
 	var end time.Time
 	if vtec.End == nil {
-		end = segment.UGC.Expires
+		end = segment.Expires
 		handler.log.Info("VTEC end time is nil. Defaulting to UGC expiry time.")
 	} else {
 		end = *vtec.End
@@ -140,7 +182,7 @@ func (handler *vtecHandler) updateTimes(vtec awips.VTEC, event *vtec.VTECEvent,
 	case "CAN":
 		fallthrough
 	case "UPG":
-		event.Expires = segment.UGC.Expires
+		event.Expires = segment.Expires
 		event.Ends = product.Issued.UTC()
 	case "EXP":
 		event.Expires = end
@@ -149,7 +191,7 @@ func (handler *vtecHandler) updateTimes(vtec awips.VTEC, event *vtec.VTECEvent,
 		fallthrough
 	case "EXB":
 		event.Ends = end
-		event.Expires = segment.UGC.Expires
+		event.Expires = segment.Expires
 	default:
 		// NEW and CON
 		if event.Ends.Before(end) {
@@ -160,3 +202,155 @@ func (handler *vtecHandler) updateTimes(vtec awips.VTEC, event *vtec.VTECEvent,
 		}
 	}
 }
+
+// Build the update that records the VTEC action of a segment against an event
+func (handler *vtecHandler) buildUpdate(v awips.VTEC, event *vtec.VTECEvent, segment awips.TextProductSegment) *vtec.VTECUpdate {
+	product := handler.product
+
+	starts := event.Starts
+	if v.Start != nil {
+		starts = *v.Start
+	}
+	ends := event.Ends
+	if v.End != nil {
+		ends = *v.End
+	}
+
+	update := &vtec.VTECUpdate{
+		Issued:       *product.Issued,
+		Starts:       starts,
+		Expires:      segment.Expires,
+		Ends:         ends,
+		Text:         segment.Text,
+		Product:      product.ProductID,
+		WFO:          v.WFO,
+		Action:       v.Action,
+		Class:        v.Class,
+		Phenomena:    v.Phenomena,
+		Significance: v.Significance,
+		EventNumber:  v.EventNumber,
+		Year:         event.Year,
+		Title:        event.Title,
+		IsEmergency:  event.IsEmergency,
+		IsPDS:        event.IsPDS,
+		UGC:          []string{},
+	}
+
+	if segment.LatLon != nil {
+		update.Polygon = util.PolygonFromAwips(*segment.LatLon.Polygon)
+	}
+
+	if segment.TML != nil {
+		tml := segment.TML
+		update.Direction = &tml.Direction
+		update.Speed = &tml.Speed
+		update.SpeedText = &tml.SpeedString
+		update.TMLTime = &tml.Time
+		update.Location = util.LocationFromTML(*tml)
+	}
+
+	if segment.UGC != nil {
+		update.UGC = segment.UGC.Codes()
+	}
+
+	tags := segment.Tags
+	update.Tornado = string(tags.Tornado)
+	update.Damage = string(tags.Damage)
+	update.HailThreat = string(tags.HailThreat)
+	update.WindThreat = string(tags.WindThreat)
+	update.FlashFlood = string(tags.FlashFlood)
+	update.RainfallTag = tags.ExpectedRainfall
+	update.FloodTagDam = string(tags.DamFailure)
+	update.SpoutTag = string(tags.Spout)
+	update.SnowSquall = string(tags.SnowSquall)
+	update.SnowSquallTag = tags.SnowSquallImpact
+	if tags.Hail != nil {
+		update.HailTag = &tags.Hail.Size
+	}
+	if tags.Wind != nil {
+		update.WindTag = &tags.Wind.Speed
+		update.WindTagUnit = tags.Wind.Unit
+	}
+
+	return update
+}
+
+// Create or update the state of a single county/zone for an event relative to the action.
+// Only the zones listed in the segment are touched so partial cancellations and area expansions leave other zones alone.
+func (handler *vtecHandler) updateUGC(v awips.VTEC, event *vtec.VTECEvent, update *vtec.VTECUpdate, segment awips.TextProductSegment, code string) error {
+	product := handler.product
+
+	ctx, cancel := context.WithTimeout(handler.ctx, 10*time.Second)
+	defer cancel()
+
+	id, err := handler.repo.GetUGCID(ctx, code)
+	if err != nil {
+		return err
+	}
+
+	ugc, err := handler.repo.GetUGC(ctx, event, id)
+	if err != nil {
+		return err
+	}
+
+	end := segment.Expires
+	if v.End != nil {
+		end = *v.End
+	}
+
+	if ugc == nil {
+		// The zone is new to the event, either from a NEW or an EXA/EXB expansion
+		starts := *product.Issued
+		if v.Start != nil {
+			starts = *v.Start
+		}
+
+		ugc = &vtec.VTECUGC{
+			WFO:          event.WFO,
+			Phenomena:    event.Phenomena,
+			Significance: event.Significance,
+			EventNumber:  event.EventNumber,
+			UGC:          id,
+			Issued:       *product.Issued,
+			Starts:       starts,
+			Expires:      segment.Expires,
+			Ends:         end,
+			EndInitial:   end,
+			Action:       v.Action,
+			Latest:       update.ID,
+			Year:         event.Year,
+		}
+
+		switch v.Action {
+		case "CAN", "UPG":
+			ugc.Ends = product.Issued.UTC()
+		case "EXP":
+			ugc.Expires = end
+		}
+
+		return handler.repo.CreateUGC(ctx, ugc)
+	}
+
+	ugc.Action = v.Action
+	ugc.Latest = update.ID
+
+	switch v.Action {
+	case "CAN", "UPG":
+		ugc.Expires = segment.Expires
+		ugc.Ends = product.Issued.UTC()
+	case "EXP":
+		ugc.Expires = end
+		ugc.Ends = end
+	case "EXT", "EXB":
+		ugc.Expires = segment.Expires
+		ugc.Ends = end
+	default:
+		// NEW, EXA, CON and COR
+		ugc.Expires = segment.Expires
+		if v.End != nil {
+			ugc.Ends = end
+		}
+	}
+
+	return handler.repo.UpdateUGC(ctx, ugc)
+}
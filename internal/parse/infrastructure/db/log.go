@@ -0,0 +1,54 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	models "github.com/metdatasystem/mds-awips/pkg/db"
+)
+
+type logRepository struct {
+	db DBTX
+}
+
+func NewLogRepository(db DBTX) *logRepository {
+	return &logRepository{db: db}
+}
+
+// Get the products that could not be stored since the given time, newest first. These were either not parsed or
+// dead lettered after failing every retry. Each has a single failed log with the reason, which also has the text.
+func (r *logRepository) GetFailures(ctx context.Context, since time.Time) ([]models.Log, error) {
+	rows, err := r.db.Query(ctx, `
+	SELECT l.id, l.created_at, l.time, l.level, COALESCE(l.product, ''), l.awips, l.wmo,
+	COALESCE(l.text, p.data, ''), l.message, l.failed FROM awips.logs l
+	LEFT JOIN awips.products p ON p.product_id = l.product
+	WHERE l.failed AND l.time >= $1 ORDER BY l.time DESC;
+	`, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	logs := []models.Log{}
+	for rows.Next() {
+		var log models.Log
+		err = rows.Scan(
+			&log.ID,
+			&log.CreatedAt,
+			&log.Time,
+			&log.Level,
+			&log.Product,
+			&log.AWIPS,
+			&log.WMO,
+			&log.Text,
+			&log.Message,
+			&log.Failed,
+		)
+		if err != nil {
+			return nil, err
+		}
+		logs = append(logs, log)
+	}
+
+	return logs, rows.Err()
+}
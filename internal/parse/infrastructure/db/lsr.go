@@ -0,0 +1,26 @@
+package db
+
+import (
+	"context"
+
+	"github.com/metdatasystem/mds-awips/internal/parse/domain/lsr"
+)
+
+type lsrRepository struct {
+	db DBTX
+}
+
+func NewLSRRepository(db DBTX) *lsrRepository {
+	return &lsrRepository{db: db}
+}
+
+// Inserts a storm report into the database.
+func (r *lsrRepository) CreateLSR(ctx context.Context, l *lsr.LSR) error {
+	err := r.db.QueryRow(ctx, `
+	INSERT INTO lsr.reports(product, wfo, time, event, magnitude, magnitude_qualifier, magnitude_units, city, county,
+	state, source, remarks, geom, corrected, summary) VALUES
+	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15) RETURNING id, created_at;
+	`, l.Product, l.WFO, l.Time, l.Event, l.Magnitude, l.MagnitudeQualifier, l.MagnitudeUnits, l.City, l.County,
+		l.State, l.Source, l.Remarks, l.Geom, l.Corrected, l.Summary).Scan(&l.ID, &l.CreatedAt)
+	return err
+}
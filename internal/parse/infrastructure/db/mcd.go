@@ -0,0 +1,26 @@
+package db
+
+import (
+	"context"
+
+	"github.com/metdatasystem/mds-awips/internal/parse/domain/mcd"
+)
+
+type mcdRepository struct {
+	db DBTX
+}
+
+func NewMCDRepository(db DBTX) *mcdRepository {
+	return &mcdRepository{db: db}
+}
+
+// Inserts an MCD into the database.
+func (r *mcdRepository) CreateMCD(ctx context.Context, m *mcd.MCD) error {
+	err := r.db.QueryRow(ctx, `
+	INSERT INTO spc.mcds(product, number, year, issued, expires, concerning, geom, watch_probability,
+	most_prob_tornado, most_prob_gust, most_prob_hail, watches) VALUES
+	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12) RETURNING id, created_at;
+	`, m.Product, m.Number, m.Year, m.Issued, m.Expires, m.Concerning, m.Geom, m.WatchProbability,
+		m.MostProbTornado, m.MostProbGust, m.MostProbHail, m.Watches).Scan(&m.ID, &m.CreatedAt)
+	return err
+}
@@ -0,0 +1,107 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	models "github.com/metdatasystem/mds-awips/pkg/db"
+)
+
+// Selects stored products. Empty fields are not filtered on.
+type ProductFilter struct {
+	Since     time.Time // Issued at or after
+	Until     time.Time // Issued before
+	Office    string    // The issuing office, e.g. OKX
+	AWIPS     string    // The AWIPS identifier, e.g. TOROKX
+	ProductID string
+}
+
+type productRepository struct {
+	db DBTX
+}
+
+func NewProductRepository(db DBTX) *productRepository {
+	return &productRepository{db: db}
+}
+
+// Get the IDs of the stored products matching the filter in the order they were issued.
+func (r *productRepository) GetProductIDs(ctx context.Context, filter ProductFilter) ([]int, error) {
+	rows, err := r.db.Query(ctx, `
+	SELECT id FROM awips.products
+	WHERE ($1::timestamptz IS NULL OR issued >= $1)
+	AND ($2::timestamptz IS NULL OR issued < $2)
+	AND ($3 = '' OR source = $3)
+	AND ($4 = '' OR awips = $4)
+	AND ($5 = '' OR product_id = $5)
+	ORDER BY issued, id;
+	`, nullTime(filter.Since), nullTime(filter.Until), filter.Office, filter.AWIPS, filter.ProductID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []int{}
+	for rows.Next() {
+		var id int
+		err = rows.Scan(&id)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+func (r *productRepository) GetProductByID(ctx context.Context, id int) (*models.TextProduct, error) {
+	product := models.TextProduct{}
+	err := r.db.QueryRow(ctx, `
+	SELECT id, product_id, created_at, received_at, issued, source, data, wmo, awips, bbb FROM awips.products
+	WHERE id = $1;
+	`, id).Scan(
+		&product.ID,
+		&product.ProductID,
+		&product.CreatedAt,
+		&product.ReceivedAt,
+		&product.Issued,
+		&product.Source,
+		&product.Data,
+		&product.WMO,
+		&product.AWIPS,
+		&product.BBB,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &product, nil
+}
+
+// Removes everything derived from a product by the routes so it can be handled again without duplicating them.
+// VTEC events, updates and UGCs are kept as the state of an event depends on every product before it. They are only
+// rebuilt after being truncated.
+func (r *productRepository) DeleteDerived(ctx context.Context, productID string) error {
+	_, err := r.db.Exec(ctx, `
+	DELETE FROM vtec.tropical_threats WHERE product = $1;
+	`, productID)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.Exec(ctx, `
+	DELETE FROM spc.mcds WHERE product = $1;
+	`, productID)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.Exec(ctx, `
+	DELETE FROM lsr.reports WHERE product = $1;
+	`, productID)
+	return err
+}
+
+func nullTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
@@ -2,23 +2,25 @@ package db
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
-	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5"
 	"github.com/metdatasystem/mds-awips/internal/parse/domain/vtec"
 	"github.com/metdatasystem/mds-awips/pkg/awips"
 )
 
 type vtecRepository struct {
-	db *pgxpool.Pool
+	db DBTX
 }
 
-func NewVTECRepository(db *pgxpool.Pool) *vtecRepository {
+func NewVTECRepository(db DBTX) *vtecRepository {
 	return &vtecRepository{db: db}
 }
 
 // Get a VTEC event by its ID.
 func (r *vtecRepository) GetEventByID(ctx context.Context, id int) (*vtec.VTECEvent, error) {
-	rows, err := r.db.Query(ctx, `SELECT * FROM vtec_events WHERE id = $1`, id)
+	rows, err := r.db.Query(ctx, `SELECT * FROM vtec.events WHERE id = $1`, id)
 	if err != nil {
 		return nil, err
 	}
@@ -97,12 +99,131 @@ func (r *vtecRepository) GetEventByVTEC(ctx context.Context, v awips.VTEC, year
 
 // Inserts an event into the database.
 func (r *vtecRepository) CreateEvent(ctx context.Context, event *vtec.VTECEvent) error {
-	_, err := r.db.Exec(ctx, `
+	err := r.db.QueryRow(ctx, `
 	INSERT INTO vtec.events(issued, starts, expires, ends, end_initial, class, phenomena, wfo, 
 	significance, event_number, year, title, is_emergency, is_pds, polygon_start) VALUES
-	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15);
+	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15) RETURNING id, created_at, updated_at;
 	`, event.Issued, event.Starts, event.Expires, event.Ends, event.EndInitial, event.Class,
 		event.Phenomena, event.WFO, event.Significance, event.EventNumber, event.Year, event.Title,
-		event.IsEmergency, event.IsPDS, event.PolygonStart)
+		event.IsEmergency, event.IsPDS, event.PolygonStart).Scan(&event.ID, &event.CreatedAt, &event.UpdatedAt)
+	return err
+}
+
+// Updates the times and flags of an existing event.
+func (r *vtecRepository) UpdateEvent(ctx context.Context, event *vtec.VTECEvent) error {
+	err := r.db.QueryRow(ctx, `
+	UPDATE vtec.events SET expires = $2, ends = $3, title = $4, is_emergency = $5, is_pds = $6, updated_at = NOW()
+	WHERE id = $1 RETURNING updated_at;
+	`, event.ID, event.Expires, event.Ends, event.Title, event.IsEmergency, event.IsPDS).Scan(&event.UpdatedAt)
+	return err
+}
+
+// Inserts an event update into the database.
+func (r *vtecRepository) CreateUpdate(ctx context.Context, update *vtec.VTECUpdate) error {
+	err := r.db.QueryRow(ctx, `
+	INSERT INTO vtec.updates(issued, starts, expires, ends, text, product, wfo, action, class, phenomena,
+	significance, event_number, year, title, is_emergency, is_pds, polygon, direction, location, speed,
+	speed_text, tml_time, ugc, tornado, damage, hail_threat, hail_tag, wind_threat, wind_tag, wind_tag_unit,
+	flash_flood, rainfall_tag, flood_tag_dam, spout_tag, snow_squall, snow_squall_tag) VALUES
+	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22,
+	$23, $24, $25, $26, $27, $28, $29, $30, $31, $32, $33, $34, $35, $36) RETURNING id, created_at;
+	`, update.Issued, update.Starts, update.Expires, update.Ends, update.Text, update.Product, update.WFO,
+		update.Action, update.Class, update.Phenomena, update.Significance, update.EventNumber, update.Year,
+		update.Title, update.IsEmergency, update.IsPDS, update.Polygon, update.Direction, update.Location,
+		update.Speed, update.SpeedText, update.TMLTime, update.UGC, update.Tornado, update.Damage,
+		update.HailThreat, update.HailTag, update.WindThreat, update.WindTag, update.WindTagUnit,
+		update.FlashFlood, update.RainfallTag, update.FloodTagDam, update.SpoutTag, update.SnowSquall,
+		update.SnowSquallTag).Scan(&update.ID, &update.CreatedAt)
+	return err
+}
+
+// Get the ID of the current definition of a UGC code, e.g. NCC063.
+func (r *vtecRepository) GetUGCID(ctx context.Context, code string) (int, error) {
+	var id int
+	err := r.db.QueryRow(ctx, `
+	SELECT id FROM postgis.ugcs WHERE ugc = $1 ORDER BY id DESC LIMIT 1;
+	`, code).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, fmt.Errorf("%w: %s", vtec.ErrUGCNotFound, code)
+	}
+	return id, err
+}
+
+// Get the state of a single UGC for an event.
+func (r *vtecRepository) GetUGC(ctx context.Context, event *vtec.VTECEvent, ugc int) (*vtec.VTECUGC, error) {
+	rows, err := r.db.Query(ctx, `
+	SELECT * FROM vtec.ugcs WHERE
+	wfo = $1 AND phenomena = $2 AND significance = $3 AND event_number = $4 AND year = $5 AND ugc = $6
+	`, event.WFO, event.Phenomena, event.Significance, event.EventNumber, event.Year, ugc)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return nil, rows.Err() // The UGC is not part of the event yet
+	}
+	var u vtec.VTECUGC
+	err = rows.Scan(
+		&u.ID,
+		&u.CreatedAt,
+		&u.UpdatedAt,
+		&u.WFO,
+		&u.Phenomena,
+		&u.Significance,
+		&u.EventNumber,
+		&u.UGC,
+		&u.Issued,
+		&u.Starts,
+		&u.Expires,
+		&u.Ends,
+		&u.EndInitial,
+		&u.Action,
+		&u.Latest,
+		&u.Year,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+// Inserts the state of a UGC for an event.
+func (r *vtecRepository) CreateUGC(ctx context.Context, ugc *vtec.VTECUGC) error {
+	err := r.db.QueryRow(ctx, `
+	INSERT INTO vtec.ugcs(wfo, phenomena, significance, event_number, ugc, issued, starts, expires, ends,
+	end_initial, action, latest, year) VALUES
+	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13) RETURNING id, created_at, updated_at;
+	`, ugc.WFO, ugc.Phenomena, ugc.Significance, ugc.EventNumber, ugc.UGC, ugc.Issued, ugc.Starts,
+		ugc.Expires, ugc.Ends, ugc.EndInitial, ugc.Action, ugc.Latest, ugc.Year).Scan(&ugc.ID, &ugc.CreatedAt, &ugc.UpdatedAt)
+	return err
+}
+
+// Updates the state of a UGC for an event.
+func (r *vtecRepository) UpdateUGC(ctx context.Context, ugc *vtec.VTECUGC) error {
+	err := r.db.QueryRow(ctx, `
+	UPDATE vtec.ugcs SET expires = $2, ends = $3, action = $4, latest = $5, updated_at = NOW()
+	WHERE id = $1 RETURNING updated_at;
+	`, ugc.ID, ugc.Expires, ugc.Ends, ugc.Action, ugc.Latest).Scan(&ugc.UpdatedAt)
+	return err
+}
+
+// Inserts the tropical threats to a UGC.
+func (r *vtecRepository) CreateTropicalThreat(ctx context.Context, threat *vtec.TropicalThreat) error {
+	err := r.db.QueryRow(ctx, `
+	INSERT INTO vtec.tropical_threats(product, wfo, ugc, issued, events, wind, storm_surge, flooding_rain, tornado,
+	hazards) VALUES
+	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id, created_at;
+	`, threat.Product, threat.WFO, threat.UGC, threat.Issued, threat.Events, threat.Wind, threat.StormSurge,
+		threat.FloodingRain, threat.Tornado, threat.Hazards).Scan(&threat.ID, &threat.CreatedAt)
+	return err
+}
+
+// Removes every VTEC event, update, UGC and tropical threat so they can be rebuilt from the stored products.
+// MCDs are removed too as they reference the watch events by id.
+func (r *vtecRepository) Truncate(ctx context.Context) error {
+	_, err := r.db.Exec(ctx, `
+	TRUNCATE vtec.tropical_threats, vtec.ugcs, vtec.updates, vtec.events, spc.mcds RESTART IDENTITY;
+	`)
 	return err
 }
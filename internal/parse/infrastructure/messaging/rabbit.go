@@ -1,7 +1,10 @@
 package rabbit
 
 import (
+	"context"
+	"encoding/json"
 	"os"
+	"time"
 
 	amqp "github.com/rabbitmq/amqp091-go"
 )
@@ -70,3 +73,69 @@ func NewPublisherChannel(conn *amqp.Connection, exchangeName, exchangeType strin
 	return ch, nil
 
 }
+
+// Publishes JSON messages to an exchange
+type Publisher struct {
+	channel  *amqp.Channel
+	exchange string
+}
+
+func NewPublisher(channel *amqp.Channel, exchange string) *Publisher {
+	return &Publisher{
+		channel:  channel,
+		exchange: exchange,
+	}
+}
+
+func (publisher *Publisher) Publish(ctx context.Context, routingKey string, message any) error {
+	body, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	return publisher.channel.PublishWithContext(ctx,
+		publisher.exchange, // exchange
+		routingKey,         // routing key
+		false,              // mandatory
+		false,              // immediate
+		amqp.Publishing{
+			ContentType: "application/json",
+			Timestamp:   time.Now().UTC(),
+			Body:        body,
+		})
+}
+
+// Declares a fanout exchange and durable queue for messages that could not be processed
+func DeclareDeadLetter(channel *amqp.Channel, exchangeName string, queueName string) error {
+	err := channel.ExchangeDeclare(
+		exchangeName, // name
+		"fanout",     // type
+		true,         // durable
+		false,        // auto-deleted
+		false,        // internal
+		false,        // no-wait
+		nil,          // arguments
+	)
+	if err != nil {
+		return err
+	}
+
+	queue, err := channel.QueueDeclare(
+		queueName, // name
+		true,      // durable
+		false,     // delete when unused
+		false,     // exclusive
+		false,     // no-wait
+		nil,       // arguments
+	)
+	if err != nil {
+		return err
+	}
+
+	return channel.QueueBind(
+		queue.Name,   // queue name
+		"",           // routing key
+		exchangeName, // exchange
+		false,
+		nil)
+}
@@ -0,0 +1,88 @@
+package parse
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/metdatasystem/mds-awips/internal/parse/handler"
+	"github.com/metdatasystem/mds-awips/internal/parse/infrastructure/db"
+)
+
+type ReprocessConfig struct {
+	MinLog   int
+	Filter   db.ProductFilter
+	Truncate bool // Remove the VTEC tables before reprocessing so they are rebuilt. Only allowed without a filter.
+}
+
+// A product that failed to be reprocessed
+type ReprocessFailure struct {
+	ProductID string
+	Errors    []string
+}
+
+/*
+Pass stored products through the routes again in the order they were issued, such as after a parser fix.
+The products themselves are not stored again and nothing is published. Anything the routes derived from a product
+before is removed first so it is not duplicated. VTEC events are only rebuilt when truncating, as the state of an event
+depends on every product before it, otherwise products are not passed to the VTEC route.
+Returns how many products were reprocessed and those that failed.
+*/
+func Reprocess(ctx context.Context, config ReprocessConfig) (int, []ReprocessFailure, error) {
+	if config.Truncate && config.Filter != (db.ProductFilter{}) {
+		return 0, nil, errors.New("truncate removes every VTEC event so it can only be used when reprocessing all products")
+	}
+
+	pool, err := db.New()
+	if err != nil {
+		return 0, nil, err
+	}
+	defer pool.Close()
+
+	if config.Truncate {
+		slog.Warn("truncating the VTEC and MCD tables")
+		err = db.NewVTECRepository(pool).Truncate(ctx)
+		if err != nil {
+			return 0, nil, err
+		}
+	}
+
+	products := db.NewProductRepository(pool)
+
+	ids, err := products.GetProductIDs(ctx, config.Filter)
+	if err != nil {
+		return 0, nil, err
+	}
+	slog.Info("reprocessing products", "count", len(ids))
+
+	failures := []ReprocessFailure{}
+	processed := 0
+	for _, id := range ids {
+		if ctx.Err() != nil {
+			return processed, failures, ctx.Err()
+		}
+
+		product, err := products.GetProductByID(ctx, id)
+		if err != nil {
+			return processed, failures, err
+		}
+
+		h := handler.NewStored(ctx, pool, nil, config.MinLog, handler.TextProduct(*product), config.Truncate)
+		err = h.Handle()
+		processed++
+
+		// The logs are reported rather than saved as the product's logs were saved when it was first handled
+		errs := h.Errors()
+		if err != nil {
+			errs = append(errs, err.Error())
+		}
+		if len(errs) > 0 {
+			failures = append(failures, ReprocessFailure{
+				ProductID: product.ProductID,
+				Errors:    errs,
+			})
+		}
+	}
+
+	return processed, failures, nil
+}
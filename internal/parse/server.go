@@ -3,6 +3,7 @@ package parse
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"sync"
@@ -10,31 +11,46 @@ import (
 
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/metdatasystem/mds-awips/internal/parse/handler"
+	"github.com/metdatasystem/mds-awips/internal/parse/infrastructure/db"
+	rabbit "github.com/metdatasystem/mds-awips/internal/parse/infrastructure/messaging"
 	amqp "github.com/rabbitmq/amqp091-go"
 )
 
 const (
-	Exchange   = "awips.exchange"
-	Queue      = "awips.queue"
-	ParseRoute = "awips.parse"
+	Exchange           = "awips.exchange"
+	Queue              = "awips.queue"
+	ParseRoute         = "awips.parse"
+	DeadLetterExchange = "awips.dlx"
+	DeadLetterQueue    = "awips.dead"
+	ConsumerTag        = "awips.parse"
 )
 
 type Config struct {
-	MinLog int
+	MinLog       int
+	MaxRetries   int           // How many times a product is retried after failing to be stored
+	RetryBackoff time.Duration // The initial wait between retries, doubled after each attempt
+	Prefetch     int           // How many unacknowledged messages can be held at once, at least 1
+	Workers      int           // How many products can be handled at once
 }
 
 type Server struct {
-	DB        *pgxpool.Pool
-	Rabbit    *amqp.Connection
-	Consumer  *amqp.Channel
-	Publisher *amqp.Channel
-	Config    Config
-	ctx       context.Context
-	cancel    context.CancelFunc
-	wg        sync.WaitGroup
+	DB         *pgxpool.Pool
+	Rabbit     *amqp.Connection
+	Consumer   *amqp.Channel
+	Publisher  *amqp.Channel
+	DeadLetter *amqp.Channel // In confirm mode so a message is only acknowledged once it has been dead lettered
+	Config     Config
+	ctx        context.Context
+	cancel     context.CancelFunc
+	wg         sync.WaitGroup
 }
 
 func New(config Config) (*Server, error) {
+	// Without a prefetch limit the broker sends every message in the queue and the workers' queues grow without bound
+	if config.Prefetch < 1 {
+		return nil, fmt.Errorf("prefetch must be at least 1, got %d", config.Prefetch)
+	}
+
 	// Create a new database connection pool
 	db, err := db.New()
 	if err != nil {
@@ -51,21 +67,46 @@ func New(config Config) (*Server, error) {
 		return nil, fmt.Errorf("failed to create consumer channel: %v", err)
 	}
 
+	err = consumer.Qos(config.Prefetch, 0, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set consumer prefetch: %v", err)
+	}
+
 	publisher, err := rabbit.NewPublisherChannel(rabbitConn, Exchange, "topic")
 	if err != nil {
 		return nil, fmt.Errorf("failed to crete publisher channel: %v", err)
 	}
 
+	deadLetter, err := rabbitConn.Channel()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create dead letter channel: %v", err)
+	}
+
+	err = rabbit.DeclareDeadLetter(deadLetter, DeadLetterExchange, DeadLetterQueue)
+	if err != nil {
+		return nil, fmt.Errorf("failed to declare dead letter queue: %v", err)
+	}
+
+	err = deadLetter.Confirm(false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to put dead letter channel in confirm mode: %v", err)
+	}
+
+	if config.Workers < 1 {
+		config.Workers = 1
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 
 	server := Server{
-		DB:        db,
-		Rabbit:    rabbitConn,
-		Config:    config,
-		Consumer:  consumer,
-		Publisher: publisher,
-		ctx:       ctx,
-		cancel:    cancel,
+		DB:         db,
+		Rabbit:     rabbitConn,
+		Config:     config,
+		Consumer:   consumer,
+		Publisher:  publisher,
+		DeadLetter: deadLetter,
+		ctx:        ctx,
+		cancel:     cancel,
 	}
 
 	return &server, nil
@@ -74,6 +115,22 @@ func New(config Config) (*Server, error) {
 type Message struct {
 	Text       string
 	ReceivedAt time.Time
+	CCCC       string
+	TTAAII     string
+	AWIPSID    string
+	Issue      time.Time
+	ID         string
+}
+
+// The header data the source provided with the text
+func (message *Message) metadata() handler.Metadata {
+	return handler.Metadata{
+		CCCC:    message.CCCC,
+		TTAAII:  message.TTAAII,
+		AWIPSID: message.AWIPSID,
+		Issue:   message.Issue.UTC(),
+		ID:      message.ID,
+	}
 }
 
 func (server *Server) Start() {
@@ -87,13 +144,13 @@ func (server *Server) Start() {
 	}
 
 	msgs, err := server.Consumer.Consume(
-		Queue, // queue
-		"",    // consumer
-		true,  // auto-ack
-		false, // exclusive
-		false, // no-local
-		false, // no-wait
-		nil,   // args
+		Queue,       // queue
+		ConsumerTag, // consumer
+		false,       // auto-ack
+		false,       // exclusive
+		false,       // no-local
+		false,       // no-wait
+		nil,         // args
 	)
 	if err != nil {
 		slog.Error("failed to register consumer: " + err.Error())
@@ -102,34 +159,149 @@ func (server *Server) Start() {
 
 	slog.Info("\033[32m *** Consumer listening *** \033[m")
 
-	// Listen for messages and process them
+	publisher := rabbit.NewPublisher(server.Publisher, Exchange)
+	dispatcher := server.newDispatcher(publisher)
+
+	// Listen for messages and pass them to the workers. The workers are added to the wait group while this is still
+	// part of it, so Shutdown never waits while more are being added.
+	server.wg.Add(1)
 	go func() {
-		for message := range msgs {
+		defer server.wg.Done()
+		for {
+			var message amqp.Delivery
+			var ok bool
+			select {
+			case <-server.ctx.Done():
+				// Unacknowledged messages are redelivered
+				return
+			case message, ok = <-msgs:
+				if !ok {
+					return
+				}
+			}
+
 			m := &Message{}
 			err := json.Unmarshal(message.Body, m)
 			if err != nil {
-				slog.Error(err.Error())
-				return
+				slog.Error("failed to unmarshal message", "error", err)
+				server.deadLetter(message, "unmarshal: "+err.Error(), 0)
+				continue
 			}
 
-			server.wg.Add(1)
-			go func(text string, receivedAt time.Time) {
-				defer server.wg.Done() // Decrement when done
-				h := handler.New(server.DB, server.Config.MinLog, text, receivedAt)
-				h.Handle()
-				err := h.SaveLog()
-				if err != nil {
-					slog.Error("failed to save log", "error", err)
-				}
-			}(m.Text, m.ReceivedAt.UTC())
+			j := &job{
+				message:    message,
+				text:       m.Text,
+				receivedAt: m.ReceivedAt.UTC(),
+				metadata:   m.metadata(),
+			}
+
+			dispatcher.dispatch(j)
 		}
 	}()
 }
 
+// Handle a product, retrying with backoff if it fails to be stored. The message is acknowledged once it has been
+// handled or dead lettered after running out of retries.
+func (server *Server) handle(message amqp.Delivery, publisher *rabbit.Publisher, text string, receivedAt time.Time, metadata handler.Metadata) {
+	backoff := server.Config.RetryBackoff
+	attempt := 0
+
+	for {
+		attempt++
+
+		h := handler.New(server.ctx, server.DB, publisher, server.Config.MinLog, text, receivedAt, metadata)
+		err := h.Handle()
+		if err == nil {
+			break
+		}
+
+		// Shutting down is not the product's fault, leave the message unacknowledged so it is redelivered
+		if server.ctx.Err() != nil {
+			slog.Warn("stopped handling product during shutdown", "error", err)
+			return
+		}
+
+		if attempt > server.Config.MaxRetries {
+			slog.Error("product failed after all retries", "error", err, "attempts", attempt)
+			// The logs were rolled back with the product so save them separately
+			logErr := h.SaveFailure(err)
+			if logErr != nil {
+				slog.Error("failed to save log", "error", logErr)
+			}
+			server.deadLetter(message, err.Error(), attempt)
+			return
+		}
+
+		slog.Warn("failed to handle product, retrying", "error", err, "attempt", attempt, "backoff", backoff)
+		select {
+		case <-server.ctx.Done():
+			// Leave the message unacknowledged so it is redelivered
+			return
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+
+	err := message.Ack(false)
+	if err != nil {
+		slog.Error("failed to acknowledge message", "error", err)
+	}
+}
+
+// Send a message that cannot be handled to the dead letter exchange with the reason it failed, then acknowledge it
+// once the broker has confirmed it.
+func (server *Server) deadLetter(message amqp.Delivery, reason string, attempts int) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	confirmation, err := server.DeadLetter.PublishWithDeferredConfirmWithContext(ctx,
+		DeadLetterExchange, // exchange
+		message.RoutingKey, // routing key
+		false,              // mandatory
+		false,              // immediate
+		amqp.Publishing{
+			ContentType:  message.ContentType,
+			DeliveryMode: amqp.Persistent,
+			Timestamp:    time.Now().UTC(),
+			Headers: amqp.Table{
+				"x-failure-reason": reason,
+				"x-attempts":       int32(attempts),
+				"x-original-queue": Queue,
+			},
+			Body: message.Body,
+		})
+	if err == nil {
+		var confirmed bool
+		confirmed, err = confirmation.WaitContext(ctx)
+		if err == nil && !confirmed {
+			err = errors.New("dead letter was not confirmed by the broker")
+		}
+	}
+	if err != nil {
+		// Requeue the message rather than lose it
+		slog.Error("failed to dead letter message", "error", err)
+		err = message.Nack(false, true)
+		if err != nil {
+			slog.Error("failed to requeue message", "error", err)
+		}
+		return
+	}
+
+	err = message.Ack(false)
+	if err != nil {
+		slog.Error("failed to acknowledge message", "error", err)
+	}
+}
+
 func (server *Server) Shutdown() {
+	// Stop receiving messages before waiting for the products being handled
+	err := server.Consumer.Cancel(ConsumerTag, false)
+	if err != nil {
+		slog.Error("failed to cancel consumer", "error", err)
+	}
 	server.cancel()
 	server.wg.Wait()
-	err := server.Consumer.Close()
+	err = server.Consumer.Close()
 	if err != nil {
 		slog.Error("failed to close consumer", "error", err)
 	}
@@ -137,6 +309,10 @@ func (server *Server) Shutdown() {
 	if err != nil {
 		slog.Error("failed to close publisher", "error", err)
 	}
+	err = server.DeadLetter.Close()
+	if err != nil {
+		slog.Error("failed to close dead letter channel", "error", err)
+	}
 	err = server.Rabbit.Close()
 	if err != nil {
 		slog.Error("failed to close RabbitMQ connection", "error", err)
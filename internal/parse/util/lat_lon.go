@@ -18,3 +18,37 @@ func PolygonFromAwips(src awips.PolygonFeature) *geos.Geom {
 	geom.SetSRID(4326)
 	return geom
 }
+
+// Creates a point from a TML with a single location or a line string from a TML with many.
+func LocationFromTML(src awips.TML) *geos.Geom {
+	if len(src.Locations) == 0 {
+		return nil
+	}
+
+	geosMutex.Lock()
+	defer geosMutex.Unlock()
+
+	var geom *geos.Geom
+	if len(src.Locations) == 1 {
+		geom = geos.NewPointFromXY(src.Locations[0][0], src.Locations[0][1])
+	} else {
+		coords := [][]float64{}
+		for _, location := range src.Locations {
+			coords = append(coords, []float64{location[0], location[1]})
+		}
+		geom = geos.NewLineString(coords)
+	}
+
+	geom.SetSRID(4326)
+	return geom
+}
+
+func PointFromAwips(src awips.PointFeature) *geos.Geom {
+	geosMutex.Lock()
+	defer geosMutex.Unlock()
+
+	geom := geos.NewPointFromXY(src.Coordinates[0], src.Coordinates[1])
+
+	geom.SetSRID(4326)
+	return geom
+}
@@ -0,0 +1,21 @@
+package util
+
+import "time"
+
+// Products often only give the day, hour and minute of a time (ddhhmm).
+// Fill in the year and month from a reference time, usually the product issue time, compensating for the end of a month/year.
+func MergeDayTime(t time.Time, ref time.Time) time.Time {
+	ref = ref.UTC()
+	merged := time.Date(ref.Year(), ref.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, time.UTC)
+
+	// The time is at the start of the next month
+	if t.Day() < ref.Day()-15 {
+		merged = time.Date(ref.Year(), ref.Month()+1, t.Day(), t.Hour(), t.Minute(), 0, 0, time.UTC)
+	}
+	// The time is at the end of the previous month
+	if t.Day() > ref.Day()+15 {
+		merged = time.Date(ref.Year(), ref.Month()-1, t.Day(), t.Hour(), t.Minute(), 0, 0, time.UTC)
+	}
+
+	return merged
+}
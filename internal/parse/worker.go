@@ -0,0 +1,156 @@
+package parse
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/metdatasystem/mds-awips/internal/parse/handler"
+	rabbit "github.com/metdatasystem/mds-awips/internal/parse/infrastructure/messaging"
+	"github.com/metdatasystem/mds-awips/pkg/awips"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// A product waiting to be handled by a worker
+type job struct {
+	message    amqp.Delivery
+	text       string
+	receivedAt time.Time
+	metadata   handler.Metadata
+	keys       []string // The partition keys of the product
+	waiting    int      // How many of its keys the job is not yet first in line for
+}
+
+/*
+Passes products to workers. Each partition key has its own queue and a product is only handled once it is first in the
+queue of every one of its keys, so products sharing a key are always handled in the order they arrived and never at the
+same time. Products with other keys carry on even if one is waiting to be retried. At most Workers products are handled
+at once and the consumer prefetch limits how many are queued.
+*/
+type dispatcher struct {
+	server *Server
+	handle func(j *job)
+	mu     sync.Mutex
+	queues map[string][]*job // The jobs holding or waiting for each key in the order they arrived
+	slots  chan struct{}
+}
+
+func (server *Server) newDispatcher(publisher *rabbit.Publisher) *dispatcher {
+	return &dispatcher{
+		server: server,
+		handle: func(j *job) {
+			server.handle(j.message, publisher, j.text, j.receivedAt, j.metadata)
+		},
+		queues: map[string][]*job{},
+		slots:  make(chan struct{}, server.Config.Workers),
+	}
+}
+
+// Queue the product behind any others that share a key with it. This never blocks.
+func (d *dispatcher) dispatch(j *job) {
+	j.keys = partitionKeys(j.text, j.metadata)
+
+	d.mu.Lock()
+	for _, key := range j.keys {
+		if len(d.queues[key]) > 0 {
+			j.waiting++
+		}
+		d.queues[key] = append(d.queues[key], j)
+	}
+	ready := j.waiting == 0
+	d.mu.Unlock()
+
+	if ready {
+		d.start(j)
+	}
+}
+
+// Handle the job once a worker is free. It is called by either the consumer or a finished job, both of which are
+// still part of the wait group.
+func (d *dispatcher) start(j *job) {
+	d.server.wg.Add(1)
+	go func() {
+		defer d.server.wg.Done()
+
+		select {
+		case d.slots <- struct{}{}:
+		case <-d.server.ctx.Done():
+			// Unacknowledged messages are redelivered
+			return
+		}
+		d.handle(j)
+		<-d.slots
+
+		d.done(j)
+	}()
+}
+
+// Remove the handled job from the front of its queues and start any jobs that were only waiting for it
+func (d *dispatcher) done(j *job) {
+	ready := []*job{}
+
+	d.mu.Lock()
+	for _, key := range j.keys {
+		queue := d.queues[key][1:]
+		if len(queue) == 0 {
+			delete(d.queues, key)
+			continue
+		}
+		d.queues[key] = queue
+
+		next := queue[0]
+		next.waiting--
+		if next.waiting == 0 {
+			ready = append(ready, next)
+		}
+	}
+	d.mu.Unlock()
+
+	for _, next := range ready {
+		d.start(next)
+	}
+}
+
+/*
+Products that update VTEC events have a key for each event of its WFO, phenomena, significance and event number, e.g.
+KRAH.TO.W.0175, so a TOR and every SVS that follows it are handled in order. Products without VTEC are keyed by their
+AWIPS identifier and issuing office, e.g. AFDRAH/KRAH, from the source's header when present.
+*/
+func partitionKeys(text string, metadata handler.Metadata) []string {
+	// Anything that fails to parse is not stored, so the events it would key on do not matter
+	vtecs, _ := awips.ParseVTEC(text)
+
+	keys := []string{}
+	for _, v := range vtecs {
+		key := fmt.Sprintf("%s.%s.%s.%04d", v.WFO, v.Phenomena, v.Significance, v.EventNumber)
+		if !slices.Contains(keys, key) {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) > 0 {
+		slices.Sort(keys)
+		return keys
+	}
+
+	return []string{productKey(text, metadata)}
+}
+
+func productKey(text string, metadata handler.Metadata) string {
+	if metadata.AWIPSID != "" && metadata.CCCC != "" {
+		return metadata.AWIPSID + "/" + metadata.CCCC
+	}
+
+	office := ""
+	wmo, err := awips.ParseWMO(text)
+	if err == nil {
+		office = wmo.Office
+	}
+
+	awipsHeader, err := awips.ParseAWIPS(text)
+	if err == nil {
+		return awipsHeader.Original + "/" + office
+	}
+
+	return wmo.Datatype + "/" + office
+}
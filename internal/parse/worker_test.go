@@ -0,0 +1,138 @@
+package parse
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/metdatasystem/mds-awips/internal/parse/handler"
+)
+
+const testTOR = `WFUS52 KRAH 262336
+TORRAH
+
+/O.NEW.KRAH.TO.W.0175.250626T2336Z-250627T0015Z/
+`
+
+const testSVS = `WWUS52 KRAH 262350
+SVSRAH
+
+/O.CON.KRAH.TO.W.0175.000000T0000Z-250627T0015Z/
+`
+
+const testSVSMultiple = `WWUS52 KRAH 262355
+SVSRAH
+
+/O.CAN.KRAH.TO.W.0176.000000T0000Z-250627T0030Z/
+$$
+/O.CON.KRAH.TO.W.0175.000000T0000Z-250627T0015Z/
+$$
+/O.CON.KRAH.TO.W.0175.000000T0000Z-250627T0015Z/
+`
+
+const testAFD = `FXUS62 KRAH 262000
+AFDRAH
+
+Area Forecast Discussion
+`
+
+func TestPartitionKeys(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		metadata handler.Metadata
+		expected []string
+	}{
+		{"warning", testTOR, handler.Metadata{}, []string{"KRAH.TO.W.0175"}},
+		{"statement", testSVS, handler.Metadata{}, []string{"KRAH.TO.W.0175"}},
+		{"several events", testSVSMultiple, handler.Metadata{}, []string{"KRAH.TO.W.0175", "KRAH.TO.W.0176"}},
+		{"no VTEC", testAFD, handler.Metadata{}, []string{"AFDRAH/KRAH"}},
+		{"source header", testAFD, handler.Metadata{AWIPSID: "AFDRAH", CCCC: "KRAH"}, []string{"AFDRAH/KRAH"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			keys := partitionKeys(test.text, test.metadata)
+			if !slices.Equal(keys, test.expected) {
+				t.Errorf("expected keys %v, got %v", test.expected, keys)
+			}
+		})
+	}
+
+	// The whole point of the keys
+	if !slices.Equal(partitionKeys(testTOR, handler.Metadata{}), partitionKeys(testSVS, handler.Metadata{})) {
+		t.Errorf("expected a TOR and its SVS to have the same key")
+	}
+}
+
+func TestDispatcherOrder(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	server := &Server{Config: Config{Workers: 4}, ctx: ctx, cancel: cancel}
+
+	var mu sync.Mutex
+	active := map[string]bool{}
+	order := []string{}
+
+	d := &dispatcher{
+		server: server,
+		queues: map[string][]*job{},
+		slots:  make(chan struct{}, server.Config.Workers),
+	}
+	d.handle = func(j *job) {
+		mu.Lock()
+		for _, key := range j.keys {
+			if active[key] {
+				t.Errorf("%s was handled while another product with key %s was", j.metadata.ID, key)
+			}
+			active[key] = true
+		}
+		order = append(order, j.metadata.ID)
+		mu.Unlock()
+
+		time.Sleep(10 * time.Millisecond)
+
+		mu.Lock()
+		for _, key := range j.keys {
+			active[key] = false
+		}
+		mu.Unlock()
+	}
+
+	jobs := []struct {
+		id   string
+		text string
+	}{
+		{"tor", testTOR},
+		{"afd", testAFD},
+		{"svs", testSVS},
+		{"multiple", testSVSMultiple},
+		{"svs again", testSVS},
+	}
+	for _, j := range jobs {
+		d.dispatch(&job{text: j.text, metadata: handler.Metadata{ID: j.id}})
+	}
+	server.wg.Wait()
+
+	if len(order) != len(jobs) {
+		t.Fatalf("expected %d products to be handled, got %v", len(jobs), order)
+	}
+
+	// Products of the same event are handled in the order they arrived
+	event := []string{}
+	for _, id := range order {
+		if id != "afd" {
+			event = append(event, id)
+		}
+	}
+	expected := []string{"tor", "svs", "multiple", "svs again"}
+	if !slices.Equal(event, expected) {
+		t.Errorf("expected the event to be handled in the order %v, got %v", expected, event)
+	}
+
+	if len(d.queues) != 0 {
+		t.Errorf("expected every queue to be removed, got %v", d.queues)
+	}
+}
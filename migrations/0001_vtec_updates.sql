@@ -0,0 +1,53 @@
+-- Each VTEC action in each segment of a product, the history of an event
+CREATE TABLE IF NOT EXISTS vtec.updates (
+    id serial PRIMARY KEY,
+    created_at timestamptz NOT NULL DEFAULT NOW(),
+    issued timestamptz NOT NULL,
+    starts timestamptz,
+    expires timestamptz NOT NULL,
+    ends timestamptz,
+    text text NOT NULL,
+    product text NOT NULL,
+    wfo text NOT NULL,
+    action text NOT NULL,
+    class text NOT NULL,
+    phenomena text NOT NULL,
+    significance text NOT NULL,
+    event_number integer NOT NULL,
+    year integer NOT NULL,
+    title text NOT NULL,
+    is_emergency boolean NOT NULL DEFAULT FALSE,
+    is_pds boolean NOT NULL DEFAULT FALSE,
+    polygon geometry(Polygon, 4326),
+    direction integer,
+    location geometry(Geometry, 4326), -- A point, or a line for a TML with several locations
+    speed integer,
+    speed_text text,
+    tml_time timestamptz,
+    ugc text[] NOT NULL,
+    tornado text NOT NULL DEFAULT '',
+    damage text NOT NULL DEFAULT '',
+    hail_threat text NOT NULL DEFAULT '',
+    hail_tag double precision, -- Inches
+    wind_threat text NOT NULL DEFAULT '',
+    wind_tag integer,
+    wind_tag_unit text NOT NULL DEFAULT '', -- MPH or KTS
+    flash_flood text NOT NULL DEFAULT '',
+    rainfall_tag text NOT NULL DEFAULT '',
+    flood_tag_dam text NOT NULL DEFAULT '',
+    spout_tag text NOT NULL DEFAULT '',
+    snow_squall text NOT NULL DEFAULT '',
+    snow_squall_tag text NOT NULL DEFAULT ''
+);
+
+-- Tables created before the tags were typed stored them as text, e.g. 1.00IN and 60MPH
+ALTER TABLE vtec.updates ADD COLUMN IF NOT EXISTS wind_tag_unit text NOT NULL DEFAULT '';
+UPDATE vtec.updates SET wind_tag_unit = substring(wind_tag::text FROM '(MPH|KTS)')
+WHERE wind_tag_unit = '' AND wind_tag::text ~ '(MPH|KTS)';
+ALTER TABLE vtec.updates ALTER COLUMN hail_tag TYPE double precision
+    USING NULLIF(substring(hail_tag::text FROM '[0-9]*\.?[0-9]+'), '')::double precision;
+ALTER TABLE vtec.updates ALTER COLUMN wind_tag TYPE integer
+    USING NULLIF(substring(wind_tag::text FROM '[0-9]+'), '')::integer;
+
+CREATE INDEX IF NOT EXISTS updates_event_idx ON vtec.updates (wfo, phenomena, significance, event_number, year);
+CREATE INDEX IF NOT EXISTS updates_product_idx ON vtec.updates (product);
@@ -0,0 +1,22 @@
+-- The state of each county/zone of a VTEC event. The columns are in the order the parse service reads them.
+CREATE TABLE IF NOT EXISTS vtec.ugcs (
+    id serial PRIMARY KEY,
+    created_at timestamptz NOT NULL DEFAULT NOW(),
+    updated_at timestamptz NOT NULL DEFAULT NOW(),
+    wfo text NOT NULL,
+    phenomena text NOT NULL,
+    significance text NOT NULL,
+    event_number integer NOT NULL,
+    ugc integer NOT NULL, -- postgis.ugcs
+    issued timestamptz NOT NULL,
+    starts timestamptz NOT NULL,
+    expires timestamptz NOT NULL,
+    ends timestamptz NOT NULL,
+    end_initial timestamptz NOT NULL,
+    action text NOT NULL,
+    latest integer NOT NULL, -- The vtec.updates row that last changed the UGC
+    year integer NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS ugcs_event_ugc_idx ON vtec.ugcs (wfo, phenomena, significance, event_number, year, ugc);
+CREATE INDEX IF NOT EXISTS ugcs_latest_idx ON vtec.ugcs (latest);
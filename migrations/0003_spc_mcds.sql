@@ -0,0 +1,22 @@
+CREATE SCHEMA IF NOT EXISTS spc;
+
+-- Mesoscale discussions
+CREATE TABLE IF NOT EXISTS spc.mcds (
+    id serial PRIMARY KEY,
+    created_at timestamptz NOT NULL DEFAULT NOW(),
+    product text NOT NULL,
+    number integer NOT NULL,
+    year integer NOT NULL,
+    issued timestamptz NOT NULL,
+    expires timestamptz NOT NULL,
+    concerning text NOT NULL,
+    geom geometry(Polygon, 4326),
+    watch_probability integer NOT NULL DEFAULT 0,
+    most_prob_tornado text NOT NULL DEFAULT '',
+    most_prob_gust text NOT NULL DEFAULT '',
+    most_prob_hail text NOT NULL DEFAULT '',
+    watches integer[] NOT NULL DEFAULT '{}' -- vtec.events of the watches concerned
+);
+
+CREATE INDEX IF NOT EXISTS mcds_number_idx ON spc.mcds (year, number);
+CREATE INDEX IF NOT EXISTS mcds_product_idx ON spc.mcds (product);
@@ -0,0 +1,18 @@
+-- The logs of each product handled by the parse service. The text is stored with one log of a product.
+CREATE TABLE IF NOT EXISTS awips.logs (
+    id serial PRIMARY KEY,
+    created_at timestamptz NOT NULL DEFAULT NOW(),
+    time timestamptz NOT NULL,
+    level text NOT NULL,
+    product text, -- awips.products product_id, if the product was stored
+    awips text NOT NULL DEFAULT '',
+    wmo text NOT NULL DEFAULT '',
+    text text,
+    message text NOT NULL
+);
+
+-- The log with the reason a product could not be stored
+ALTER TABLE awips.logs ADD COLUMN IF NOT EXISTS failed boolean NOT NULL DEFAULT FALSE;
+
+CREATE INDEX IF NOT EXISTS logs_failed_idx ON awips.logs (time) WHERE failed;
+CREATE INDEX IF NOT EXISTS logs_product_idx ON awips.logs (product);
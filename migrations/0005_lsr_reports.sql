@@ -0,0 +1,25 @@
+CREATE SCHEMA IF NOT EXISTS lsr;
+
+-- Local storm reports, one for each report in a product
+CREATE TABLE IF NOT EXISTS lsr.reports (
+    id serial PRIMARY KEY,
+    created_at timestamptz NOT NULL DEFAULT NOW(),
+    product text NOT NULL,
+    wfo text NOT NULL,
+    time timestamptz NOT NULL,
+    event text NOT NULL,
+    magnitude double precision NOT NULL DEFAULT 0, -- Zero when there is no magnitude
+    magnitude_qualifier text NOT NULL DEFAULT '',
+    magnitude_units text NOT NULL DEFAULT '',
+    city text NOT NULL,
+    county text NOT NULL,
+    state text NOT NULL,
+    source text NOT NULL,
+    remarks text NOT NULL DEFAULT '',
+    geom geometry(Point, 4326),
+    corrected boolean NOT NULL DEFAULT FALSE,
+    summary boolean NOT NULL DEFAULT FALSE
+);
+
+CREATE INDEX IF NOT EXISTS reports_product_idx ON lsr.reports (product);
+CREATE INDEX IF NOT EXISTS reports_time_idx ON lsr.reports (time);
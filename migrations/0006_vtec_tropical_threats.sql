@@ -0,0 +1,18 @@
+-- The threats to each county/zone in a TCV
+CREATE TABLE IF NOT EXISTS vtec.tropical_threats (
+    id serial PRIMARY KEY,
+    created_at timestamptz NOT NULL DEFAULT NOW(),
+    product text NOT NULL,
+    wfo text NOT NULL,
+    ugc integer NOT NULL, -- postgis.ugcs
+    issued timestamptz NOT NULL,
+    events integer[] NOT NULL DEFAULT '{}', -- vtec.events of the segment
+    wind text NOT NULL DEFAULT '', -- Threat levels, empty if not assessed
+    storm_surge text NOT NULL DEFAULT '',
+    flooding_rain text NOT NULL DEFAULT '',
+    tornado text NOT NULL DEFAULT '',
+    hazards jsonb NOT NULL DEFAULT '[]'
+);
+
+CREATE INDEX IF NOT EXISTS tropical_threats_product_idx ON vtec.tropical_threats (product);
+CREATE INDEX IF NOT EXISTS tropical_threats_ugc_idx ON vtec.tropical_threats (ugc, issued);
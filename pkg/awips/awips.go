@@ -27,10 +27,10 @@ func ParseAWIPS(text string) (AWIPS, error) {
 	awipsRegex := regexp.MustCompile(AWIPSRegexp)
 	original := awipsRegex.FindString(text)
 	if original == "" {
-		return AWIPS{}, errors.New("could not find AWIPS header")
+		return AWIPS{}, NewParseError(ComponentAWIPS, text, -1, errors.New("could not find AWIPS header"))
 	}
 	// Trim the end
-	original = strings.TrimSpace(original[:len(original)-1])
+	original = strings.TrimSpace(original)
 
 	// Product is the first three characters
 	product := strings.TrimSpace(original[0:3])
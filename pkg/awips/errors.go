@@ -0,0 +1,81 @@
+package awips
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// The parts of a product that can fail to be parsed
+const (
+	ComponentWMO    = "wmo"
+	ComponentAWIPS  = "awips"
+	ComponentIssued = "issued"
+	ComponentUGC    = "ugc"
+	ComponentVTEC   = "vtec"
+	ComponentHVTEC  = "hvtec"
+	ComponentLatLon = "latlon"
+	ComponentTML    = "tml"
+	ComponentTags   = "tags"
+)
+
+// The most text kept around an error
+const snippetLength = 80
+
+// An error found while parsing part of a product. The offset and line are relative to the text given to the parser.
+type ParseError struct {
+	Component string `json:"component"`
+	Offset    int    `json:"offset"`  // Byte offset of the problem, -1 if it could not be found at all
+	Line      int    `json:"line"`    // 1 based line of the offset, 0 if it could not be found at all
+	Snippet   string `json:"snippet"` // The text at the offset
+	Err       error  `json:"-"`
+}
+
+// Create a ParseError at the offset in the text. Use an offset of -1 if what was being parsed was not found.
+func NewParseError(component string, text string, offset int, err error) *ParseError {
+	parseErr := &ParseError{
+		Component: component,
+		Offset:    offset,
+		Err:       err,
+	}
+
+	if offset < 0 || offset > len(text) {
+		parseErr.Offset = -1
+		return parseErr
+	}
+
+	parseErr.Line = strings.Count(text[:offset], "\n") + 1
+
+	snippet := text[offset:]
+	if end := strings.IndexByte(snippet, '\n'); end != -1 {
+		snippet = snippet[:end]
+	}
+	if len(snippet) > snippetLength {
+		snippet = snippet[:snippetLength]
+	}
+	parseErr.Snippet = strings.TrimSpace(snippet)
+
+	return parseErr
+}
+
+// Create a ParseError with a formatted message
+func parseError(component string, text string, offset int, format string, args ...any) *ParseError {
+	return NewParseError(component, text, offset, fmt.Errorf(format, args...))
+}
+
+func (e *ParseError) Error() string {
+	if e.Offset < 0 {
+		return fmt.Sprintf("%s: %v", e.Component, e.Err)
+	}
+	return fmt.Sprintf("%s: line %d: %v: %q", e.Component, e.Line, e.Err, e.Snippet)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// Check if the error is a ParseError of the given component
+func IsParseError(err error, component string) bool {
+	var parseErr *ParseError
+	return errors.As(err, &parseErr) && parseErr.Component == component
+}
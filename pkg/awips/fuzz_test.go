@@ -0,0 +1,148 @@
+package awips
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// Seeds shared by the fuzz tests, including inputs that used to panic
+var fuzzSeeds = []string{
+	testSVR,
+	"WFUS51 KOKX 262336\nTOROKX\n",
+	"NCC063-135-145>147-VAC083-262345-\n",
+	"063-NCC135-262345-\n",
+	"NCC1-262345-\n",
+	"/O.NEW.KRAH.SV.W.0175.250626T2336Z-250627T0015Z/",
+	"/O.NEW.KRAH.SV.W.0175.250626T2336Z-250627T0015Z/\n/LKRN7.1.ER.250627T0000Z.250627T1200Z.250628T0000Z.NO/",
+	"LAT...LON 3581 7875 3573 7903 3589",
+	"LAT...LON 3581 12",
+	"LAT...LON 358178",
+	"TIME...MOT...LOC",
+	"TIME...MOT...LOC 2336Z 25 21KT 3583 7896",
+	"TORNADO...RADAR INDICATED\nMAX HAIL SIZE...1.75 IN\nMAX WIND GUST...60 MPH\n",
+	"736 PM EDT Thu Jun 26 2025",
+	"736 PM XYZ Thu Jun 26 2025",
+}
+
+// Checks that a returned error is a ParseError
+func checkParseError(t *testing.T, err error) {
+	if err == nil {
+		return
+	}
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) {
+		t.Errorf("expected a ParseError, got %T: %v", err, err)
+	}
+}
+
+func addSeeds(f *testing.F) {
+	for _, seed := range fuzzSeeds {
+		f.Add(seed)
+	}
+}
+
+func FuzzParseWMO(f *testing.F) {
+	addSeeds(f)
+	f.Fuzz(func(t *testing.T, text string) {
+		_, err := ParseWMO(text)
+		checkParseError(t, err)
+	})
+}
+
+func FuzzParseAWIPS(f *testing.F) {
+	addSeeds(f)
+	f.Fuzz(func(t *testing.T, text string) {
+		_, err := ParseAWIPS(text)
+		checkParseError(t, err)
+	})
+}
+
+func FuzzGetIssuedTime(f *testing.F) {
+	addSeeds(f)
+	f.Fuzz(func(t *testing.T, text string) {
+		_, err := GetIssuedTime(text)
+		checkParseError(t, err)
+	})
+}
+
+func FuzzParseUGC(f *testing.F) {
+	addSeeds(f)
+	f.Fuzz(func(t *testing.T, text string) {
+		_, err := ParseUGC(text)
+		checkParseError(t, err)
+	})
+}
+
+func FuzzParseVTEC(f *testing.F) {
+	addSeeds(f)
+	f.Fuzz(func(t *testing.T, text string) {
+		_, errs := ParseVTEC(text)
+		for _, err := range errs {
+			checkParseError(t, err)
+		}
+	})
+}
+
+func FuzzParseHVTEC(f *testing.F) {
+	addSeeds(f)
+	f.Fuzz(func(t *testing.T, text string) {
+		_, err := ParseHVTEC(text)
+		checkParseError(t, err)
+	})
+}
+
+func FuzzParseLatLon(f *testing.F) {
+	addSeeds(f)
+	f.Fuzz(func(t *testing.T, text string) {
+		_, err := ParseLatLon(text)
+		checkParseError(t, err)
+	})
+}
+
+func FuzzParseTML(f *testing.F) {
+	addSeeds(f)
+	f.Fuzz(func(t *testing.T, text string) {
+		_, err := ParseTML(text, time.Date(2025, time.June, 26, 23, 36, 0, 0, time.UTC))
+		checkParseError(t, err)
+	})
+}
+
+func FuzzParseTags(f *testing.F) {
+	addSeeds(f)
+	f.Fuzz(func(t *testing.T, text string) {
+		_, errs := ParseTags(text)
+		for _, err := range errs {
+			checkParseError(t, err)
+		}
+	})
+}
+
+func FuzzNew(f *testing.F) {
+	addSeeds(f)
+	f.Fuzz(func(t *testing.T, text string) {
+		_, err := New(text)
+		checkParseError(t, err)
+	})
+}
+
+func TestParseErrorPosition(t *testing.T) {
+	_, err := ParseUGC("WFUS51 KOKX 262336\nTOROKX\n\nNCC063-12-262345-\n")
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) {
+		t.Fatalf("expected a ParseError, got %v", err)
+	}
+
+	if parseErr.Component != ComponentUGC {
+		t.Errorf("expected component '%s', got '%s'", ComponentUGC, parseErr.Component)
+	}
+	if parseErr.Line != 4 {
+		t.Errorf("expected line 4, got %d", parseErr.Line)
+	}
+	if parseErr.Offset != 27 {
+		t.Errorf("expected offset 27, got %d", parseErr.Offset)
+	}
+	if parseErr.Snippet != "NCC063-12-262345-" {
+		t.Errorf("expected snippet 'NCC063-12-262345-', got '%s'", parseErr.Snippet)
+	}
+}
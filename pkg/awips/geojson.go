@@ -0,0 +1,109 @@
+package awips
+
+/*
+GeoJSON (RFC 7946) representation of a text product. Each segment with a LAT...LON polygon becomes a feature,
+as does each storm location in a TIME...MOT...LOC line.
+*/
+
+type FeatureCollection struct {
+	Type     string    `json:"type"` // FeatureCollection
+	Features []Feature `json:"features"`
+}
+
+type Feature struct {
+	Type       string         `json:"type"` // Feature
+	Geometry   any            `json:"geometry"`
+	Properties map[string]any `json:"properties"`
+}
+
+type PointFeature struct {
+	Type        string    `json:"type"` // Point
+	Coordinates []float64 `json:"coordinates"`
+}
+
+type LineStringFeature struct {
+	Type        string      `json:"type"` // LineString
+	Coordinates [][]float64 `json:"coordinates"`
+}
+
+// The product's polygons and storm locations as a feature collection
+func (product *TextProduct) GeoJSON() FeatureCollection {
+	collection := FeatureCollection{
+		Type:     "FeatureCollection",
+		Features: []Feature{},
+	}
+
+	for i, segment := range product.Segments {
+		properties := map[string]any{
+			"awips":   product.AWIPS.Original,
+			"office":  product.Office,
+			"issued":  product.Issued,
+			"segment": i,
+			"expires": segment.Expires,
+		}
+		if len(segment.VTEC) > 0 {
+			vtec := []string{}
+			for _, v := range segment.VTEC {
+				vtec = append(vtec, v.Original)
+			}
+			properties["vtec"] = vtec
+		}
+		if segment.UGC != nil {
+			properties["ugc"] = segment.UGC.Codes()
+		}
+
+		if segment.LatLon != nil && segment.LatLon.Polygon != nil {
+			polygon := copyProperties(properties)
+			polygon["kind"] = "polygon"
+			polygon["tags"] = segment.Tags
+			collection.Features = append(collection.Features, Feature{
+				Type:       "Feature",
+				Geometry:   segment.LatLon.Polygon,
+				Properties: polygon,
+			})
+		}
+
+		if segment.TML != nil && len(segment.TML.Locations) > 0 {
+			tml := copyProperties(properties)
+			tml["kind"] = "tml"
+			tml["time"] = segment.TML.Time
+			tml["direction"] = segment.TML.Direction
+			tml["speed"] = segment.TML.Speed
+			collection.Features = append(collection.Features, Feature{
+				Type:       "Feature",
+				Geometry:   segment.TML.Geometry(),
+				Properties: tml,
+			})
+		}
+	}
+
+	return collection
+}
+
+// The storm location as a point, or a line if there are several
+func (tml *TML) Geometry() any {
+	coordinates := [][]float64{}
+	for _, location := range tml.Locations {
+		coordinates = append(coordinates, []float64{location[0], location[1]})
+	}
+
+	if len(coordinates) == 1 {
+		return PointFeature{
+			Type:        "Point",
+			Coordinates: coordinates[0],
+		}
+	}
+
+	return LineStringFeature{
+		Type:        "LineString",
+		Coordinates: coordinates,
+	}
+}
+
+func copyProperties(properties map[string]any) map[string]any {
+	c := make(map[string]any, len(properties))
+	for k, v := range properties {
+		c[k] = v
+	}
+	return c
+}
@@ -0,0 +1,69 @@
+package awips
+
+import "testing"
+
+const testSVR = `WUUS52 KRAH 262336
+SVRRAH
+
+NCC063-135-270015-
+/O.NEW.KRAH.SV.W.0175.250626T2336Z-250627T0015Z/
+
+BULLETIN - IMMEDIATE BROADCAST REQUESTED
+Severe Thunderstorm Warning
+National Weather Service Raleigh NC
+736 PM EDT Thu Jun 26 2025
+
+The National Weather Service in Raleigh has issued a
+
+* Severe Thunderstorm Warning for...
+  Durham County in central North Carolina...
+
+LAT...LON 3581 7875 3573 7903 3589 7912 3600 7888
+TIME...MOT...LOC 2336Z 254DEG 21KT 3583 7896
+
+HAIL THREAT...RADAR INDICATED
+MAX HAIL SIZE...1.00 IN
+WIND THREAT...RADAR INDICATED
+MAX WIND GUST...60 MPH
+
+$$
+`
+
+func TestProductGeoJSON(t *testing.T) {
+	product, err := New(testSVR)
+	if err != nil {
+		t.Fatalf("failed to parse product: %v", err)
+	}
+
+	if len(product.Segments) != 1 {
+		t.Fatalf("expected 1 segment, got %d", len(product.Segments))
+	}
+	if product.Segments[0].TML == nil {
+		t.Fatalf("expected segment to have a TML")
+	}
+
+	collection := product.GeoJSON()
+	if collection.Type != "FeatureCollection" {
+		t.Errorf("expected type FeatureCollection, got '%s'", collection.Type)
+	}
+	if len(collection.Features) != 2 {
+		t.Fatalf("expected 2 features, got %d", len(collection.Features))
+	}
+
+	polygon := collection.Features[0]
+	if geometry, ok := polygon.Geometry.(*PolygonFeature); !ok || len(geometry.Coordinates[0]) != 5 {
+		t.Errorf("expected a polygon with 5 points, got %v", polygon.Geometry)
+	}
+	if codes, ok := polygon.Properties["ugc"].([]string); !ok || len(codes) != 2 {
+		t.Errorf("expected 2 UGC codes, got %v", polygon.Properties["ugc"])
+	}
+
+	tml := collection.Features[1]
+	point, ok := tml.Geometry.(PointFeature)
+	if !ok {
+		t.Fatalf("expected a point, got %T", tml.Geometry)
+	}
+	if point.Coordinates[0] != -78.96 || point.Coordinates[1] != 35.83 {
+		t.Errorf("expected point -78.96, 35.83, got %v", point.Coordinates)
+	}
+}
@@ -0,0 +1,144 @@
+package awips
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+var HVTECSeverity = map[string]string{
+	"N": "None",
+	"0": "Areal or Flash Flood",
+	"1": "Minor",
+	"2": "Moderate",
+	"3": "Major",
+	"U": "Unknown",
+}
+
+var HVTECCause = map[string]string{
+	"ER": "Excessive Rainfall",
+	"SM": "Snowmelt",
+	"RS": "Rain and Snowmelt",
+	"DM": "Dam or Levee Failure",
+	"IJ": "Ice Jam",
+	"GO": "Glacier-Dammed Lake Outburst",
+	"IC": "Rain and/or Snowmelt and/or Ice Jam",
+	"FS": "Upstream Flooding plus Storm Surge",
+	"FT": "Upstream Flooding plus Tidal Effects",
+	"ET": "Elevated Upstream Flow plus Tidal Effects",
+	"WT": "Wind and/or Tidal Effects",
+	"DR": "Upstream Dam or Reservoir Release",
+	"MC": "Other Multiple Causes",
+	"OT": "Other Effects",
+	"UU": "Unknown",
+}
+
+var HVTECRecord = map[string]string{
+	"NO": "Record flood not expected",
+	"NR": "Near record or record flood expected",
+	"UU": "Flood without a period of record to compare",
+	"OO": "Areal or Flash Flood",
+}
+
+// Hydrologic VTEC as defined in NWS Directive 10-1703
+type HVTEC struct {
+	Original    string     `json:"original"`
+	NWSLI       string     `json:"nwsli"`
+	Severity    string     `json:"severity"`
+	Cause       string     `json:"cause"`
+	BeginString string     `json:"begin_string"`
+	Begin       *time.Time `json:"begin"`
+	CrestString string     `json:"crest_string"`
+	Crest       *time.Time `json:"crest"`
+	EndString   string     `json:"end_string"`
+	End         *time.Time `json:"end"`
+	Record      string     `json:"record"`
+}
+
+const HVTECRegexp = `([A-Z0-9]{5})\.([A-Z0-9])\.([A-Z]{2})\.([0-9]{6}T[0-9]{4}Z)\.([0-9]{6}T[0-9]{4}Z)\.([0-9]{6}T[0-9]{4}Z)\.([A-Z]{2})`
+
+// Attempts to find and parse a H-VTEC in the text. Returns nil if none is found.
+func ParseHVTEC(text string) (*HVTEC, error) {
+	hvtecRegex := regexp.MustCompile(HVTECRegexp)
+	index := hvtecRegex.FindStringIndex(text)
+	if index == nil {
+		return nil, nil
+	}
+	original := text[index[0]:index[1]]
+
+	segments := strings.Split(original, ".")
+
+	if len(segments) != 7 {
+		return nil, parseError(ComponentHVTEC, text, index[0], "length of segments is %d, expected 7 for %s", len(segments), original)
+	}
+
+	nwsli := segments[0]
+
+	severity := segments[1]
+	if _, ok := HVTECSeverity[severity]; !ok {
+		return nil, parseError(ComponentHVTEC, text, index[0], "invalid flood severity %s for %s", severity, original)
+	}
+
+	cause := segments[2]
+	if _, ok := HVTECCause[cause]; !ok {
+		return nil, parseError(ComponentHVTEC, text, index[0], "invalid immediate cause %s for %s", cause, original)
+	}
+
+	begin, err := parseHVTECTime(segments[3])
+	if err != nil {
+		return nil, parseError(ComponentHVTEC, text, index[0], "failed to parse begin time %s for %s", segments[3], original)
+	}
+
+	crest, err := parseHVTECTime(segments[4])
+	if err != nil {
+		return nil, parseError(ComponentHVTEC, text, index[0], "failed to parse crest time %s for %s", segments[4], original)
+	}
+
+	end, err := parseHVTECTime(segments[5])
+	if err != nil {
+		return nil, parseError(ComponentHVTEC, text, index[0], "failed to parse end time %s for %s", segments[5], original)
+	}
+
+	record := segments[6]
+	if _, ok := HVTECRecord[record]; !ok {
+		return nil, parseError(ComponentHVTEC, text, index[0], "invalid flood record status %s for %s", record, original)
+	}
+
+	return &HVTEC{
+		Original:    original,
+		NWSLI:       nwsli,
+		Severity:    severity,
+		Cause:       cause,
+		BeginString: segments[3],
+		Begin:       begin,
+		CrestString: segments[4],
+		Crest:       crest,
+		EndString:   segments[5],
+		End:         end,
+		Record:      record,
+	}, nil
+}
+
+// H-VTEC times of all zeros are not known and are returned as nil
+func parseHVTECTime(s string) (*time.Time, error) {
+	if s == "000000T0000Z" {
+		return nil, nil
+	}
+	t, err := time.Parse("060102T1504Z", s)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (hvtec *HVTEC) SeverityString() string {
+	return HVTECSeverity[hvtec.Severity]
+}
+
+func (hvtec *HVTEC) CauseString() string {
+	return HVTECCause[hvtec.Cause]
+}
+
+func (hvtec *HVTEC) RecordString() string {
+	return HVTECRecord[hvtec.Record]
+}
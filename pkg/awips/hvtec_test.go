@@ -0,0 +1,133 @@
+package awips
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestHVTECParse(t *testing.T) {
+	hvtec, err := ParseHVTEC(`
+/O.NEW.KJAN.FL.W.0012.250401T1800Z-250405T0600Z/
+/BRDM6.2.ER.250401T1800Z.250403T0000Z.250404T1800Z.NO/
+`)
+	if err != nil {
+		t.Fatalf("failed to parse H-VTEC: %v", err)
+	}
+
+	if hvtec == nil {
+		t.Fatal("expected H-VTEC, got nil")
+	}
+
+	if hvtec.NWSLI != "BRDM6" {
+		t.Errorf("expected NWSLI 'BRDM6', got '%s'", hvtec.NWSLI)
+	}
+	if hvtec.Severity != "2" {
+		t.Errorf("expected severity '2', got '%s'", hvtec.Severity)
+	}
+	if hvtec.SeverityString() != "Moderate" {
+		t.Errorf("expected severity string 'Moderate', got '%s'", hvtec.SeverityString())
+	}
+	if hvtec.Cause != "ER" {
+		t.Errorf("expected cause 'ER', got '%s'", hvtec.Cause)
+	}
+	expectedBegin := time.Date(2025, time.April, 1, 18, 0, 0, 0, time.UTC)
+	if hvtec.Begin == nil || !hvtec.Begin.Equal(expectedBegin) {
+		t.Errorf("expected begin time '%s', got '%v'", expectedBegin, hvtec.Begin)
+	}
+	expectedCrest := time.Date(2025, time.April, 3, 0, 0, 0, 0, time.UTC)
+	if hvtec.Crest == nil || !hvtec.Crest.Equal(expectedCrest) {
+		t.Errorf("expected crest time '%s', got '%v'", expectedCrest, hvtec.Crest)
+	}
+	expectedEnd := time.Date(2025, time.April, 4, 18, 0, 0, 0, time.UTC)
+	if hvtec.End == nil || !hvtec.End.Equal(expectedEnd) {
+		t.Errorf("expected end time '%s', got '%v'", expectedEnd, hvtec.End)
+	}
+	if hvtec.Record != "NO" {
+		t.Errorf("expected record 'NO', got '%s'", hvtec.Record)
+	}
+	if hvtec.Original != "BRDM6.2.ER.250401T1800Z.250403T0000Z.250404T1800Z.NO" {
+		t.Errorf("expected original 'BRDM6.2.ER.250401T1800Z.250403T0000Z.250404T1800Z.NO', got '%s'", hvtec.Original)
+	}
+}
+
+func TestHVTECNilDates(t *testing.T) {
+	hvtec, err := ParseHVTEC("/00000.N.ER.000000T0000Z.000000T0000Z.000000T0000Z.OO/")
+	if err != nil {
+		t.Fatalf("failed to parse H-VTEC: %v", err)
+	}
+
+	if hvtec.Begin != nil {
+		t.Errorf("expected begin time to be nil, got '%s'", hvtec.Begin)
+	}
+	if hvtec.Crest != nil {
+		t.Errorf("expected crest time to be nil, got '%s'", hvtec.Crest)
+	}
+	if hvtec.End != nil {
+		t.Errorf("expected end time to be nil, got '%s'", hvtec.End)
+	}
+}
+
+func TestHVTECJSON(t *testing.T) {
+	hvtec, err := ParseHVTEC("/BRDM6.2.ER.250401T1800Z.250403T0000Z.250404T1800Z.NO/")
+	if err != nil {
+		t.Fatalf("failed to parse H-VTEC: %v", err)
+	}
+
+	data, err := json.Marshal(hvtec)
+	if err != nil {
+		t.Fatalf("failed to marshal H-VTEC: %v", err)
+	}
+	fields := map[string]any{}
+	err = json.Unmarshal(data, &fields)
+	if err != nil {
+		t.Fatalf("failed to unmarshal H-VTEC: %v", err)
+	}
+
+	for _, key := range []string{"begin_string", "crest_string", "end_string"} {
+		if _, ok := fields[key]; !ok {
+			t.Errorf("expected key '%s' in %s", key, data)
+		}
+	}
+	for _, key := range []string{"BeginString", "CrestString", "EndString"} {
+		if _, ok := fields[key]; ok {
+			t.Errorf("expected no key '%s' in %s", key, data)
+		}
+	}
+}
+
+func TestHVTECMissing(t *testing.T) {
+	hvtec, err := ParseHVTEC("/O.NEW.KRAH.SV.W.0175.250626T2336Z-250627T0015Z/")
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if hvtec != nil {
+		t.Errorf("expected nil H-VTEC, got '%s'", hvtec.Original)
+	}
+}
+
+func TestHVTECParseInvalid(t *testing.T) {
+	// Invalid severity
+	_, err := ParseHVTEC("/BRDM6.7.ER.250401T1800Z.250403T0000Z.250404T1800Z.NO/")
+	if err == nil {
+		t.Error("expected error for invalid severity")
+	}
+
+	// Invalid cause
+	_, err = ParseHVTEC("/BRDM6.2.XX.250401T1800Z.250403T0000Z.250404T1800Z.NO/")
+	if err == nil {
+		t.Error("expected error for invalid cause")
+	}
+
+	// Invalid crest time
+	_, err = ParseHVTEC("/BRDM6.2.ER.250401T1800Z.251332T2461Z.250404T1800Z.NO/")
+	if err == nil {
+		t.Error("expected error for invalid crest time")
+	}
+
+	// Invalid record status
+	_, err = ParseHVTEC("/BRDM6.2.ER.250401T1800Z.250403T0000Z.250404T1800Z.XX/")
+	if err == nil {
+		t.Error("expected error for invalid record status")
+	}
+}
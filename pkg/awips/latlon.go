@@ -2,6 +2,7 @@ package awips
 
 import (
 	"errors"
+	"fmt"
 	"regexp"
 	"strconv"
 )
@@ -22,9 +23,17 @@ type MultiPolygonFeature struct {
 	Coordinates [][][][]float64 `json:"coordinates"`
 }
 
+// Parses a point from either a single 8 digit segment (as used by the SPC, e.g. 35637993) or a latitude and longitude pair
 func ParsePoint(segments []string) (*[]float64, error) {
+	if len(segments) == 0 {
+		return nil, errors.New("no point segments")
+	}
+
 	if len(segments[0]) > 5 {
 		s := segments[0]
+		if len(segments) > 1 || len(s) != 8 {
+			return nil, fmt.Errorf("point string %s was not the correct size", s)
+		}
 		latInit, err := strconv.Atoi(s[0:4])
 		if err != nil {
 			return nil, errors.New("failed to parse point latitude")
@@ -37,13 +46,14 @@ func ParsePoint(segments []string) (*[]float64, error) {
 
 		lat := (float64(latInit) / 100)
 		lon := (float64(lonInit) / 100) * -1
-		if lon > -20.0 {
+		// The leading 1 is dropped from longitudes west of 100, e.g. 12450 is written as 2450
+		if lon > -30.0 {
 			lon = lon + -100
 		}
 
 		return &[]float64{lon, lat}, nil
 	} else {
-		if len(segments) > 2 {
+		if len(segments) != 2 {
 			return nil, errors.New("point string was not the correct size")
 		}
 		latInit, err := strconv.Atoi(segments[0])
@@ -66,29 +76,35 @@ func ParsePoint(segments []string) (*[]float64, error) {
 
 func ParseLatLon(text string) (*LatLon, error) {
 	latlonRegexp := regexp.MustCompile(`(?m)(^LAT\.\.\.LON\s+(\d+\s*)+)`)
-	original := latlonRegexp.FindString(text)
-
-	if original == "" {
+	index := latlonRegexp.FindStringIndex(text)
+	if index == nil {
 		return nil, nil
 	}
+	original := text[index[0]:index[1]]
 
 	segmentRegexp := regexp.MustCompile("[0-9]{4,}")
 	segments := segmentRegexp.FindAllString(original, -1)
+	if len(segments) == 0 {
+		return nil, parseError(ComponentLatLon, text, index[0], "no points found")
+	}
 
 	points := [][]float64{}
 	if len(segments[0]) > 5 {
 		for _, s := range segments {
 			point, err := ParsePoint([]string{s})
 			if err != nil {
-				return nil, err
+				return nil, NewParseError(ComponentLatLon, text, index[0], err)
 			}
 			points = append(points, *point)
 		}
 	} else {
+		if len(segments)%2 != 0 {
+			return nil, parseError(ComponentLatLon, text, index[0], "odd number of coordinates %d", len(segments))
+		}
 		for i := 0; i < len(segments); i += 2 {
 			point, err := ParsePoint([]string{segments[i], segments[i+1]})
 			if err != nil {
-				return nil, err
+				return nil, NewParseError(ComponentLatLon, text, index[0], err)
 			}
 			points = append(points, *point)
 		}
@@ -97,6 +113,11 @@ func ParseLatLon(text string) (*LatLon, error) {
 		points = append(points, points[0])
 	}
 
+	// A closed polygon needs at least 3 distinct points
+	if len(points) < 4 {
+		return nil, parseError(ComponentLatLon, text, index[0], "polygon has only %d points", len(points)-1)
+	}
+
 	polygon := PolygonFeature{
 		Type:        "Polygon",
 		Coordinates: [][][]float64{points},
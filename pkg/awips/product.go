@@ -1,7 +1,6 @@
 package awips
 
 import (
-	"errors"
 	"regexp"
 	"strings"
 	"time"
@@ -27,14 +26,15 @@ type TextProduct struct {
 
 // A text product segment
 type TextProductSegment struct {
-	Text    string            `json:"text"`
-	VTEC    []VTEC            `json:"vtec"`
-	UGC     *UGC              `json:"ugc"`
-	Expires time.Time         `json:"expires"` // The product expiry time as defined in NWS Directive 10-1701
-	Ends    time.Time         `json:"ends"`    // The event end time as defined in NWS Directive 10-1701
-	LatLon  *LatLon           `json:"latlon"`
-	Tags    map[string]string `json:"tags"`
-	TML     *TML              `json:"tml"`
+	Text    string     `json:"text"`
+	VTEC    []VTEC     `json:"vtec"`
+	HVTEC   *HVTEC     `json:"hvtec"`
+	UGC     *UGC       `json:"ugc"`
+	Expires time.Time  `json:"expires"` // The product expiry time as defined in NWS Directive 10-1701
+	Ends    time.Time  `json:"ends"`    // The event end time as defined in NWS Directive 10-1701
+	LatLon  *LatLon    `json:"latlon"`
+	Tags    ImpactTags `json:"tags"`
+	TML     *TML       `json:"tml"`
 }
 
 // Attempts to parse the given text into a text product including segments & VTEC
@@ -88,9 +88,10 @@ func GetIssuedTime(text string) (time.Time, error) {
 
 	// Find when the product was issued
 	issuedRegexp := regexp.MustCompile("[0-9]{3,4} ((AM|PM) [A-Za-z]{3,4}|UTC) ([A-Za-z]{3} ){2}[0-9]{1,2} [0-9]{4}")
-	issuedString := issuedRegexp.FindString(text)
+	index := issuedRegexp.FindStringIndex(text)
 
-	if issuedString != "" {
+	if index != nil {
+		issuedString := text[index[0]:index[1]]
 		// Find if the timezone is UTC
 		utcRegexp := regexp.MustCompile("UTC")
 		utc := utcRegexp.MatchString(issuedString)
@@ -105,7 +106,7 @@ func GetIssuedTime(text string) (time.Time, error) {
 			tzString := strings.ToUpper(strings.Split(issuedString, " ")[2])
 			tz := Timezones[tzString]
 			if tz == nil {
-				return issued, errors.New("missing timezone " + tzString + " in issued string")
+				return issued, parseError(ComponentIssued, text, index[0], "missing timezone %s in issued string", tzString)
 			}
 			split := strings.Split(issuedString, " ")
 			t := split[0]
@@ -118,13 +119,17 @@ func GetIssuedTime(text string) (time.Time, error) {
 		}
 
 		if err != nil {
-			return issued, errors.New("could not parse issued date line")
+			return issued, parseError(ComponentIssued, text, index[0], "could not parse issued date line")
 		}
 	}
 
 	return issued, nil
 }
 
+/*
+Split the product into its segments. A segment is kept without whatever part of it failed to parse, such as its UGC or
+polygon, and the errors are returned alongside the segments.
+*/
 func GetSegments(text string, issued time.Time, wmo WMO) ([]TextProductSegment, []error) {
 	// Segment the product
 	splits := strings.Split(text, "$$")
@@ -143,7 +148,7 @@ func GetSegments(text string, issued time.Time, wmo WMO) ([]TextProductSegment,
 		ugc, err := ParseUGC(segment)
 		if err != nil {
 			errors = append(errors, err)
-			return nil, errors
+			ugc = nil
 		}
 		expires := time.Now().UTC()
 		if ugc != nil {
@@ -161,29 +166,41 @@ func GetSegments(text string, issued time.Time, wmo WMO) ([]TextProductSegment,
 			errors = append(errors, e...)
 		}
 
-		latlon, err := ParseLatLon(text)
+		hvtec, err := ParseHVTEC(segment)
+		if err != nil {
+			errors = append(errors, err)
+		}
+
+		latlon, err := ParseLatLon(segment)
 		if err != nil {
 			errors = append(errors, err)
-			return nil, errors
+			latlon = nil
 		}
 
-		tags, e := ParseTags(text)
+		tags, e := ParseTags(segment)
 		if len(e) != 0 {
 			errors = append(errors, e...)
 		}
 
+		tml, err := ParseTML(segment, issued)
+		if err != nil {
+			errors = append(errors, err)
+		}
+
 		segments = append(segments, TextProductSegment{
 			Text:    segment,
 			VTEC:    vtec,
+			HVTEC:   hvtec,
 			UGC:     ugc,
 			Expires: expires,
 			LatLon:  latlon,
 			Tags:    tags,
+			TML:     tml,
 		})
 
 	}
 
-	return segments, nil
+	return segments, errors
 }
 
 func (product *TextProduct) HasVTEC() bool {
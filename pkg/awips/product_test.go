@@ -0,0 +1,85 @@
+package awips
+
+import (
+	"testing"
+	"time"
+)
+
+// A statement with a good segment followed by segments with a bad UGC, polygon and VTEC
+const testSegments = `WWUS52 KRAH 262350
+SVSRAH
+
+NCC063-270015-
+/O.CON.KRAH.SV.W.0175.000000T0000Z-250627T0015Z/
+
+The warning remains in effect for Durham County.
+
+LAT...LON 3581 7875 3573 7903 3589 7912 3600 7888
+TIME...MOT...LOC 2350Z 254DEG 21KT 3583 7896
+
+$$
+
+NCC135-273015-
+/O.CON.KRAH.SV.W.0176.000000T0000Z-250627T0015Z/
+
+The warning remains in effect for Orange County.
+
+$$
+
+NCC069-270015-
+/O.CON.KRAH.SV.W.0177.000000T0000Z-250627T0015Z/
+
+The warning remains in effect for Franklin County.
+
+LAT...LON 3581 7875 3573
+
+$$
+
+NCC077-270015-
+/O.CON.KRAH.SV.W.0178.000000T0000Z-250627T0015Z/
+/O.CON.KRAH.JJ.W.0179.000000T0000Z-250627T0015Z/
+
+The warning remains in effect for Granville County.
+
+$$
+`
+
+func TestGetSegments(t *testing.T) {
+	issued := time.Date(2025, 6, 26, 23, 50, 0, 0, time.UTC)
+	wmo := WMO{Issued: time.Date(0, 1, 26, 23, 50, 0, 0, time.UTC)}
+
+	segments, errs := GetSegments(testSegments, issued, wmo)
+
+	// Every segment is kept without what failed to parse
+	if len(segments) != 4 {
+		t.Fatalf("expected 4 segments, got %d", len(segments))
+	}
+
+	if segments[0].UGC == nil || segments[0].LatLon == nil || segments[0].TML == nil || len(segments[0].VTEC) != 1 {
+		t.Errorf("expected the first segment to be fully parsed, got %+v", segments[0])
+	}
+	expires := time.Date(2025, 6, 27, 0, 15, 0, 0, time.UTC)
+	if !segments[0].Expires.Equal(expires) {
+		t.Errorf("expected the first segment to expire at %v, got %v", expires, segments[0].Expires)
+	}
+
+	if segments[1].UGC != nil || len(segments[1].VTEC) != 1 {
+		t.Errorf("expected the second segment to have its VTEC without a UGC, got %+v", segments[1])
+	}
+	if segments[2].UGC == nil || segments[2].LatLon != nil || len(segments[2].VTEC) != 1 {
+		t.Errorf("expected the third segment to have its UGC and VTEC without a polygon, got %+v", segments[2])
+	}
+	if len(segments[3].VTEC) != 1 || segments[3].VTEC[0].EventNumber != 178 {
+		t.Errorf("expected the fourth segment to keep its valid VTEC, got %+v", segments[3].VTEC)
+	}
+
+	components := []string{ComponentUGC, ComponentLatLon, ComponentVTEC}
+	if len(errs) != len(components) {
+		t.Fatalf("expected %d errors, got %d: %v", len(components), len(errs), errs)
+	}
+	for i, component := range components {
+		if !IsParseError(errs[i], component) {
+			t.Errorf("expected error %d to be a %s error, got %v", i, component, errs[i])
+		}
+	}
+}
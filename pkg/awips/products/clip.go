@@ -0,0 +1,383 @@
+package products
+
+import (
+	"math"
+	"sort"
+)
+
+/*
+Polygon clipping with the Greiner-Hormann algorithm, used to limit outlook areas to CONUS.
+
+The algorithm cannot handle rings that touch or share edges, which outlook areas closed along the CONUS outline always
+do, so vertices of one ring that lie on the other are first moved slightly to one side of it. Only the crossings are
+found with the moved vertices, the rings keep their original coordinates.
+*/
+
+const (
+	clipTolerance = 1e-9 // How close a vertex must be to a ring to be on it
+	clipNudge     = 1e-7 // How far a vertex on a ring is moved off it
+)
+
+// A vertex of a ring to be clipped, where it is taken to be and where it really is
+type clipVertex struct {
+	point    []float64
+	original []float64
+}
+
+type clipNode struct {
+	clipVertex
+	next, prev *clipNode
+	crossing   bool
+	entry      bool // The ring enters the other ring at this crossing
+	visited    bool
+	neighbor   *clipNode // The same crossing in the other ring
+	alpha      float64   // How far along the edge the crossing is
+}
+
+// The result of clipping two rings
+type clipResult struct {
+	rings         [][][]float64 // The rings made where the boundaries cross, closed
+	crossed       bool          // If the boundaries cross at all, otherwise one is inside the other or they are apart
+	subjectInside bool          // Without any crossings, if the subject is inside the clip ring
+	clipInside    bool          // Without any crossings, if the clip ring is inside the subject
+}
+
+// Clip the polygon, an exterior and its holes as drawn, to CONUS. The exterior may be split into several polygons and
+// holes outside of CONUS are dropped. Exteriors are clockwise and holes counter-clockwise.
+func clipPolygon(polygon [][][]float64) [][][][]float64 {
+	outline := openRing(conus)
+
+	subject := perturb(openRing(polygon[0]), outline, false)
+	result := clipRings(subject, vertices(outline), false)
+
+	pieces := [][][][]float64{}
+	switch {
+	case result.crossed:
+		for _, ring := range result.rings {
+			pieces = append(pieces, [][][]float64{ring})
+		}
+	case result.subjectInside:
+		pieces = append(pieces, [][][]float64{polygon[0]})
+	case result.clipInside:
+		pieces = append(pieces, [][][]float64{closeRing(outline)})
+	}
+
+	for _, hole := range polygon[1:] {
+		next := [][][][]float64{}
+		for _, piece := range pieces {
+			next = append(next, subtract(piece, openRing(hole))...)
+		}
+		pieces = next
+	}
+
+	for _, piece := range pieces {
+		for i, ring := range piece {
+			// Drawn exteriors are clockwise, which has a negative area
+			if (i == 0) != (signedArea(ring) < 0) {
+				piece[i] = reverse(ring)
+			}
+		}
+	}
+
+	return pieces
+}
+
+// Cut the hole out of the polygon. Holes crossing its exterior change the exterior instead.
+func subtract(polygon [][][]float64, hole [][]float64) [][][][]float64 {
+	exterior := openRing(polygon[0])
+
+	// The hole is grown slightly past the exterior so no sliver is left where they share an edge
+	result := clipRings(vertices(exterior), perturb(hole, exterior, true), true)
+
+	switch {
+	case !result.crossed && result.subjectInside:
+		// Everything is in the hole
+		return nil
+	case !result.crossed && result.clipInside:
+		return [][][][]float64{append(polygon, closeRing(hole))}
+	case !result.crossed:
+		return [][][][]float64{polygon}
+	}
+
+	// Keep the other holes in whichever new exterior they are in
+	pieces := [][][][]float64{}
+	for _, ring := range result.rings {
+		piece := [][][]float64{ring}
+		for _, other := range polygon[1:] {
+			if inRing(other[0], ring) {
+				piece = append(piece, other)
+			}
+		}
+		pieces = append(pieces, piece)
+	}
+	return pieces
+}
+
+/*
+Clip the subject ring with the clip ring, both without their closing point. Returns the parts of the subject inside the
+clip ring, or outside it for a difference. The rings must not touch, see perturb.
+*/
+func clipRings(subject, clip []clipVertex, difference bool) clipResult {
+	subjectPoints := points(subject)
+	clipPoints := points(clip)
+
+	// Find where every pair of edges cross
+	subjectCrossings := make([][]*clipNode, len(subject))
+	clipCrossings := make([][]*clipNode, len(clip))
+	for i := range subject {
+		s1, s2 := subject[i].point, subject[(i+1)%len(subject)].point
+		for j := range clip {
+			c1, c2 := clip[j].point, clip[(j+1)%len(clip)].point
+			t, u, ok := intersect(s1, s2, c1, c2)
+			if !ok || t == 0 || t == 1 || u == 0 {
+				continue
+			}
+			point := lerp(s1, s2, t)
+			s := &clipNode{clipVertex: clipVertex{point, point}, crossing: true, alpha: t}
+			c := &clipNode{clipVertex: clipVertex{point, point}, crossing: true, alpha: u}
+			s.neighbor, c.neighbor = c, s
+			subjectCrossings[i] = append(subjectCrossings[i], s)
+			clipCrossings[j] = append(clipCrossings[j], c)
+		}
+	}
+
+	subjectStart, crossed := clipList(subject, subjectCrossings)
+	clipStart, _ := clipList(clip, clipCrossings)
+
+	if !crossed {
+		return clipResult{
+			subjectInside: inRing(subject[0].point, clipPoints),
+			clipInside:    inRing(clip[0].point, subjectPoints),
+		}
+	}
+
+	markEntries(subjectStart, clipPoints, difference)
+	markEntries(clipStart, subjectPoints, false)
+
+	rings := [][][]float64{}
+	for node := subjectStart; ; node = node.next {
+		if node.crossing && !node.visited {
+			if ring := traverse(node); len(ring) >= 4 {
+				rings = append(rings, ring)
+			}
+		}
+		if node.next == subjectStart {
+			break
+		}
+	}
+
+	return clipResult{rings: rings, crossed: true}
+}
+
+// Link the vertices and the crossings of each edge, in order along it, into a loop. Returns the first vertex and if
+// there were any crossings.
+func clipList(ring []clipVertex, crossings [][]*clipNode) (*clipNode, bool) {
+	var first, last *clipNode
+	crossed := false
+
+	add := func(node *clipNode) {
+		if first == nil {
+			first = node
+		} else {
+			last.next = node
+			node.prev = last
+		}
+		last = node
+	}
+
+	for i, vertex := range ring {
+		add(&clipNode{clipVertex: vertex})
+
+		sort.Slice(crossings[i], func(a, b int) bool { return crossings[i][a].alpha < crossings[i][b].alpha })
+		for _, crossing := range crossings[i] {
+			add(crossing)
+			crossed = true
+		}
+	}
+
+	last.next = first
+	first.prev = last
+	return first, crossed
+}
+
+// Mark whether the ring enters or leaves the other at each crossing. For a difference the subject is kept where it is
+// outside the other ring, so it is the other way around.
+func markEntries(first *clipNode, other [][]float64, invert bool) {
+	inside := inRing(first.point, other)
+	for node := first; ; node = node.next {
+		if node.crossing {
+			node.entry = !inside != invert
+			inside = !inside
+		}
+		if node.next == first {
+			break
+		}
+	}
+}
+
+// Follow the rings from a crossing, switching between them at each crossing, until back at the start
+func traverse(start *clipNode) [][]float64 {
+	ring := [][]float64{}
+	current := start
+
+	for !current.visited {
+		current.visited = true
+		current.neighbor.visited = true
+		ring = append(ring, current.original)
+
+		forward := current.entry
+		for {
+			if forward {
+				current = current.next
+			} else {
+				current = current.prev
+			}
+			if current.crossing {
+				break
+			}
+			ring = append(ring, current.original)
+		}
+		current = current.neighbor
+	}
+
+	return cleanRing(ring)
+}
+
+/*
+Move the vertices of the ring that lie on the other ring slightly off it, into the other ring or out of it if outward
+is set. Vertices of the other ring that lie on an edge of this ring are added to it and moved the same way. The moved
+vertices keep their original coordinates.
+*/
+func perturb(ring, other [][]float64, outward bool) []clipVertex {
+	// The inside of a clockwise ring is to the right of its edges
+	side := 1.0
+	if signedArea(closeRing(other)) > 0 {
+		side = -side
+	}
+	if outward {
+		side = -side
+	}
+
+	// Which way is into the other ring from the edge from a to b
+	normal := func(a, b []float64) []float64 {
+		dx, dy := b[0]-a[0], b[1]-a[1]
+		length := math.Hypot(dx, dy)
+		return []float64{side * dy / length, -side * dx / length}
+	}
+
+	move := func(p []float64) clipVertex {
+		var direction []float64
+		for j := range other {
+			prev, a, b := other[(j+len(other)-1)%len(other)], other[j], other[(j+1)%len(other)]
+			if math.Hypot(p[0]-a[0], p[1]-a[1]) < clipTolerance {
+				// Between the edges on either side of the vertex
+				n1, n2 := normal(prev, a), normal(a, b)
+				direction = []float64{n1[0] + n2[0], n1[1] + n2[1]}
+				if math.Hypot(direction[0], direction[1]) < clipTolerance {
+					direction = n2
+				}
+				break
+			}
+			if t, d := project(p, a, b); t > 0 && t < 1 && d < clipTolerance {
+				direction = normal(a, b)
+			}
+		}
+		if direction == nil {
+			return clipVertex{p, p}
+		}
+
+		length := math.Hypot(direction[0], direction[1])
+		moved := []float64{p[0] + direction[0]/length*clipNudge, p[1] + direction[1]/length*clipNudge}
+		return clipVertex{moved, p}
+	}
+
+	result := []clipVertex{}
+	for i, p := range ring {
+		result = append(result, move(p))
+
+		// Vertices of the other ring along this edge, in order
+		q := ring[(i+1)%len(ring)]
+		type onEdge struct {
+			t     float64
+			point []float64
+		}
+		found := []onEdge{}
+		for _, o := range other {
+			if t, d := project(o, p, q); t > 0 && t < 1 && d < clipTolerance {
+				found = append(found, onEdge{t, o})
+			}
+		}
+		sort.Slice(found, func(a, b int) bool { return found[a].t < found[b].t })
+		for _, f := range found {
+			result = append(result, move(f.point))
+		}
+	}
+
+	return result
+}
+
+// How far along the segment from a to b the point is nearest, and how far away it is
+func project(p, a, b []float64) (float64, float64) {
+	dx, dy := b[0]-a[0], b[1]-a[1]
+	t := ((p[0]-a[0])*dx + (p[1]-a[1])*dy) / (dx*dx + dy*dy)
+	q := lerp(a, b, t)
+	return t, math.Hypot(p[0]-q[0], p[1]-q[1])
+}
+
+// Remove repeated points and close the ring
+func cleanRing(ring [][]float64) [][]float64 {
+	cleaned := [][]float64{}
+	for _, p := range ring {
+		if len(cleaned) > 0 {
+			last := cleaned[len(cleaned)-1]
+			if math.Hypot(p[0]-last[0], p[1]-last[1]) < clipNudge*10 {
+				continue
+			}
+		}
+		cleaned = append(cleaned, p)
+	}
+	for len(cleaned) > 1 {
+		last := cleaned[len(cleaned)-1]
+		if math.Hypot(cleaned[0][0]-last[0], cleaned[0][1]-last[1]) >= clipNudge*10 {
+			break
+		}
+		cleaned = cleaned[:len(cleaned)-1]
+	}
+	if len(cleaned) < 3 {
+		return nil
+	}
+	return closeRing(cleaned)
+}
+
+// The ring without its closing point
+func openRing(ring [][]float64) [][]float64 {
+	if len(ring) > 1 {
+		first, last := ring[0], ring[len(ring)-1]
+		if first[0] == last[0] && first[1] == last[1] {
+			return ring[:len(ring)-1]
+		}
+	}
+	return ring
+}
+
+// The ring with its closing point
+func closeRing(ring [][]float64) [][]float64 {
+	closed := append([][]float64{}, openRing(ring)...)
+	return append(closed, closed[0])
+}
+
+func vertices(ring [][]float64) []clipVertex {
+	result := make([]clipVertex, len(ring))
+	for i, p := range ring {
+		result[i] = clipVertex{p, p}
+	}
+	return result
+}
+
+func points(ring []clipVertex) [][]float64 {
+	result := make([][]float64, len(ring))
+	for i, v := range ring {
+		result[i] = v.point
+	}
+	return result
+}
@@ -0,0 +1,127 @@
+package products
+
+import (
+	"math"
+	"testing"
+)
+
+func square(x1, y1, x2, y2 float64) [][]float64 {
+	// Clockwise, as outlook exteriors are drawn
+	return [][]float64{{x1, y1}, {x1, y2}, {x2, y2}, {x2, y1}}
+}
+
+func totalArea(rings [][][]float64) float64 {
+	total := 0.0
+	for _, ring := range rings {
+		total += math.Abs(signedArea(ring))
+	}
+	return total
+}
+
+func TestClipRings(t *testing.T) {
+	tests := []struct {
+		name       string
+		subject    [][]float64
+		clip       [][]float64
+		difference bool
+		rings      int
+		area       float64
+	}{
+		{"overlapping", square(0, 0, 2, 2), square(1, 1, 3, 3), false, 1, 1},
+		{"overlapping difference", square(0, 0, 2, 2), square(1, 1, 3, 3), true, 1, 3},
+		{"split", square(0, 0, 3, 1), square(1, -1, 2, 2), true, 2, 2},
+		{"shared edges", square(0, 0, 1, 2), square(0, 0, 2, 2), false, 1, 2},
+		{"shared edges difference", square(0, 0, 2, 2), square(0, 0, 1, 2), true, 1, 2},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := clipRings(perturb(test.subject, test.clip, false), vertices(test.clip), false)
+			if test.difference {
+				result = clipRings(vertices(test.subject), perturb(test.clip, test.subject, true), true)
+			}
+
+			// Rings that only touch do not cross, so the subject or clip ring is kept whole
+			if !result.crossed {
+				switch {
+				case result.subjectInside:
+					result.rings = [][][]float64{closeRing(test.subject)}
+				case result.clipInside:
+					result.rings = [][][]float64{closeRing(test.clip)}
+				}
+			}
+
+			if len(result.rings) != test.rings {
+				t.Fatalf("expected %d rings, got %v", test.rings, result.rings)
+			}
+			if area := totalArea(result.rings); math.Abs(area-test.area) > 1e-6 {
+				t.Errorf("expected an area of %f, got %f: %v", test.area, area, result.rings)
+			}
+		})
+	}
+
+	// Without crossings one ring may be inside the other
+	result := clipRings(vertices(square(1, 1, 2, 2)), vertices(square(0, 0, 3, 3)), false)
+	if result.crossed || !result.subjectInside || result.clipInside {
+		t.Errorf("expected the subject to be inside the clip ring, got %+v", result)
+	}
+	result = clipRings(vertices(square(0, 0, 1, 1)), vertices(square(2, 2, 3, 3)), false)
+	if result.crossed || result.subjectInside || result.clipInside {
+		t.Errorf("expected the rings to be apart, got %+v", result)
+	}
+}
+
+func TestClipPolygon(t *testing.T) {
+	// Over eastern North Carolina and the Atlantic, with a hole across the coast
+	exterior := closeRing(square(-79, 33, -73, 37))
+	hole := closeRing(reverse(square(-77, 34.5, -75, 35.5)))
+
+	pieces := clipPolygon([][][]float64{exterior, hole})
+	if len(pieces) != 1 {
+		t.Fatalf("expected 1 polygon, got %d", len(pieces))
+	}
+
+	// The hole crosses the coast so it cuts into the exterior instead
+	polygon := pieces[0]
+	if len(polygon) != 1 {
+		t.Fatalf("expected the hole to become part of the exterior, got %d rings", len(polygon))
+	}
+	ring := polygon[0]
+	if signedArea(ring) >= 0 {
+		t.Errorf("expected a clockwise exterior")
+	}
+
+	for _, check := range []struct {
+		name   string
+		point  []float64
+		inside bool
+	}{
+		{"Raleigh", []float64{-78.6, 35.8}, true},
+		{"the Outer Banks", []float64{-76, 36}, true},
+		{"the hole", []float64{-76.8, 35}, false},
+		{"the Atlantic", []float64{-74, 35}, false},
+		{"South Carolina", []float64{-80, 34}, false},
+	} {
+		if inRing(check.point, ring) != check.inside {
+			t.Errorf("expected %s to be inside: %t", check.name, check.inside)
+		}
+	}
+
+	// Everything is on or inside the outline
+	outline := closeRing(conus)
+	for _, p := range ring {
+		if !inRing(p, outline) && distanceToOutline(p) > 1e-6 {
+			t.Errorf("expected %v to be inside CONUS", p)
+		}
+	}
+
+	// Areas entirely offshore are dropped
+	if pieces := clipPolygon([][][]float64{closeRing(square(-70, 30, -65, 35))}); len(pieces) != 0 {
+		t.Errorf("expected an area in the Atlantic to be dropped, got %v", pieces)
+	}
+}
+
+func distanceToOutline(p []float64) float64 {
+	point, _ := nearestOnBoundary(p)
+	return math.Hypot(p[0]-point[0], p[1]-point[1])
+}
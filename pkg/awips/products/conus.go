@@ -0,0 +1,186 @@
+package products
+
+import "math"
+
+/*
+A simplified outline of the contiguous United States, clockwise as [lon, lat], used to close and clip SPC outlook lines.
+It only needs to be close enough to the coasts and borders that the outlook lines cross it where the SPC intends.
+*/
+var conus = [][]float64{
+	{-124.7, 48.4}, {-123.2, 49.0}, {-95.2, 49.0}, {-95.2, 49.4}, {-94.8, 49.3}, {-89.6, 48.0}, {-84.8, 46.9},
+	{-82.4, 45.3}, {-82.5, 43.0}, {-79.2, 43.5}, {-76.5, 44.2}, {-74.9, 45.0}, {-71.5, 45.0}, {-70.0, 46.7},
+	{-69.2, 47.4}, {-67.8, 47.1}, {-67.8, 45.7}, {-67.0, 44.8}, {-70.0, 43.7}, {-70.6, 42.6}, {-69.9, 41.7},
+	{-71.9, 41.3}, {-74.0, 40.5}, {-74.0, 39.5}, {-75.5, 38.6}, {-75.9, 37.0}, {-75.5, 35.2}, {-76.5, 34.6},
+	{-78.0, 33.8}, {-79.2, 33.2}, {-81.0, 31.8}, {-81.4, 30.3}, {-80.0, 26.7}, {-80.4, 25.2}, {-81.2, 25.2},
+	{-81.8, 26.1}, {-82.7, 27.5}, {-82.8, 29.0}, {-84.0, 30.0}, {-86.5, 30.4}, {-88.5, 30.3}, {-89.6, 29.2},
+	{-91.0, 29.2}, {-93.8, 29.7}, {-95.0, 29.3}, {-97.2, 27.7}, {-97.2, 25.9}, {-99.1, 26.4}, {-100.6, 28.1},
+	{-101.4, 29.8}, {-102.4, 29.8}, {-103.1, 29.0}, {-104.5, 29.6}, {-106.5, 31.8}, {-108.2, 31.8}, {-108.2, 31.3},
+	{-111.1, 31.3}, {-114.8, 32.5}, {-117.1, 32.5}, {-118.5, 34.0}, {-120.6, 34.6}, {-121.9, 36.6}, {-123.0, 38.0},
+	{-123.8, 39.8}, {-124.2, 41.9}, {-124.1, 43.7}, {-124.0, 46.3},
+}
+
+// A position along the CONUS outline, the index of the edge plus how far along it
+type boundaryPosition float64
+
+// Close an outlook line into a ring. The outlook area is to the right of the line, so the line is clipped to where it
+// is inside CONUS and then joined from its end back to its start by following the outline clockwise.
+func closeLine(line [][]float64) [][]float64 {
+	line, startPos, endPos := clipLine(line)
+	if len(line) < 2 {
+		return nil
+	}
+
+	ring := append([][]float64{}, line...)
+
+	n := len(conus)
+	first := int(math.Floor(float64(endPos))) + 1
+	last := int(math.Floor(float64(startPos)))
+	if startPos < endPos {
+		last += n
+	}
+	for i := first; i <= last; i++ {
+		ring = append(ring, conus[i%n])
+	}
+
+	return append(ring, line[0])
+}
+
+// Clip the ends of the line to the CONUS outline, returning where the ends are on the outline.
+// Ends inside CONUS are joined to the nearest point of the outline.
+func clipLine(line [][]float64) ([][]float64, boundaryPosition, boundaryPosition) {
+	type crossing struct {
+		index int // The segment of the line
+		t     float64
+		point []float64
+		pos   boundaryPosition
+	}
+
+	crossings := []crossing{}
+	for i := 0; i < len(line)-1; i++ {
+		found := []crossing{}
+		for j := range conus {
+			a, b := conus[j], conus[(j+1)%len(conus)]
+			t, u, ok := intersect(line[i], line[i+1], a, b)
+			if !ok {
+				continue
+			}
+			found = append(found, crossing{
+				index: i,
+				t:     t,
+				point: lerp(line[i], line[i+1], t),
+				pos:   boundaryPosition(float64(j) + u),
+			})
+		}
+		// Order the crossings of this segment along it
+		for x := 1; x < len(found); x++ {
+			for y := x; y > 0 && found[y].t < found[y-1].t; y-- {
+				found[y], found[y-1] = found[y-1], found[y]
+			}
+		}
+		crossings = append(crossings, found...)
+	}
+
+	clipped := append([][]float64{}, line...)
+	startOffset := 0
+
+	var startPos, endPos boundaryPosition
+
+	if !inRing(line[len(line)-1], conus) && len(crossings) > 0 {
+		c := crossings[len(crossings)-1]
+		clipped = append(clipped[:c.index+1], c.point)
+		endPos = c.pos
+	} else {
+		var point []float64
+		point, endPos = nearestOnBoundary(line[len(line)-1])
+		clipped = append(clipped, point)
+	}
+
+	if !inRing(line[0], conus) && len(crossings) > 0 {
+		c := crossings[0]
+		startOffset = c.index + 1
+		clipped = append([][]float64{c.point}, clipped[startOffset:]...)
+		startPos = c.pos
+	} else {
+		var point []float64
+		point, startPos = nearestOnBoundary(line[0])
+		clipped = append([][]float64{point}, clipped...)
+	}
+
+	return clipped, startPos, endPos
+}
+
+// The point on the CONUS outline nearest to p
+func nearestOnBoundary(p []float64) ([]float64, boundaryPosition) {
+	best := math.Inf(1)
+	var point []float64
+	var pos boundaryPosition
+
+	for j := range conus {
+		a, b := conus[j], conus[(j+1)%len(conus)]
+		dx, dy := b[0]-a[0], b[1]-a[1]
+		t := ((p[0]-a[0])*dx + (p[1]-a[1])*dy) / (dx*dx + dy*dy)
+		t = math.Max(0, math.Min(1, t))
+		q := lerp(a, b, t)
+		d := math.Hypot(p[0]-q[0], p[1]-q[1])
+		if d < best {
+			best = d
+			point = q
+			pos = boundaryPosition(float64(j) + t)
+		}
+	}
+
+	return point, pos
+}
+
+// Find where segments p1-p2 and q1-q2 cross, returning how far along each segment it is
+func intersect(p1, p2, q1, q2 []float64) (float64, float64, bool) {
+	rx, ry := p2[0]-p1[0], p2[1]-p1[1]
+	sx, sy := q2[0]-q1[0], q2[1]-q1[1]
+
+	denominator := rx*sy - ry*sx
+	if denominator == 0 {
+		return 0, 0, false
+	}
+
+	qpx, qpy := q1[0]-p1[0], q1[1]-p1[1]
+	t := (qpx*sy - qpy*sx) / denominator
+	u := (qpx*ry - qpy*rx) / denominator
+	if t < 0 || t > 1 || u < 0 || u >= 1 {
+		return 0, 0, false
+	}
+
+	return t, u, true
+}
+
+func lerp(a, b []float64, t float64) []float64 {
+	return []float64{a[0] + (b[0]-a[0])*t, a[1] + (b[1]-a[1])*t}
+}
+
+// Check if the point is inside the ring using ray casting
+func inRing(p []float64, ring [][]float64) bool {
+	inside := false
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		a, b := ring[i], ring[j]
+		if (a[1] > p[1]) != (b[1] > p[1]) && p[0] < (b[0]-a[0])*(p[1]-a[1])/(b[1]-a[1])+a[0] {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// The signed area of the ring, positive if it is counter-clockwise
+func signedArea(ring [][]float64) float64 {
+	area := 0.0
+	for i := 0; i < len(ring)-1; i++ {
+		area += ring[i][0]*ring[i+1][1] - ring[i+1][0]*ring[i][1]
+	}
+	return area / 2
+}
+
+func reverse(ring [][]float64) [][]float64 {
+	reversed := make([][]float64, len(ring))
+	for i, p := range ring {
+		reversed[len(ring)-1-i] = p
+	}
+	return reversed
+}
@@ -0,0 +1,184 @@
+package products
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/metdatasystem/mds-awips/pkg/awips"
+)
+
+const ComponentLSR = "lsr"
+
+// A single report from a Local Storm Report product
+type LSR struct {
+	Time               time.Time          `json:"time"`                // In the timezone of the product
+	Event              string             `json:"event"`               // e.g. HAIL or TSTM WND DMG
+	Magnitude          float64            `json:"magnitude"`           // Zero when there is no magnitude
+	MagnitudeQualifier string             `json:"magnitude_qualifier"` // E for estimated, M for measured, U for unknown
+	MagnitudeUnits     string             `json:"magnitude_units"`     // e.g. INCH or MPH
+	City               string             `json:"city"`                // The location, e.g. 2 NW CARY
+	County             string             `json:"county"`
+	State              string             `json:"state"`
+	Source             string             `json:"source"`
+	Remarks            string             `json:"remarks"`
+	Point              awips.PointFeature `json:"point"`
+	Corrected          bool               `json:"corrected"` // The report corrects one sent before
+	Summary            bool               `json:"summary"`   // The report is part of a summary of reports sent before
+}
+
+var (
+	lsrHeaderRegexp    = regexp.MustCompile(`(?m)^\.\.TIME\.\.\.`)
+	lsrReportRegexp    = regexp.MustCompile(`^[0-9]{4} (?:AM|PM) `)
+	lsrDateRegexp      = regexp.MustCompile(`^[0-9]{2}/[0-9]{2}/[0-9]{4}`)
+	lsrLatLonRegexp    = regexp.MustCompile(`^([0-9.]+)([NS])\s+([0-9.]+)([EW])$`)
+	lsrMagnitudeRegexp = regexp.MustCompile(`^([EMU])?([0-9.]+)\s*(.*)$`)
+)
+
+/*
+Parses the reports of a Local Storm Report. Each report is two fixed column lines followed by indented remarks:
+
+	0515 PM     TSTM WND DMG     2 NW CARY               35.80N 78.81W
+	06/26/2025                   WAKE               NC   PUBLIC
+
+	            TREES DOWN ON HOUSE.
+*/
+func ParseLSR(text string) ([]LSR, error) {
+	header := lsrHeaderRegexp.FindStringIndex(text)
+	if header == nil {
+		return nil, awips.NewParseError(ComponentLSR, text, -1, errors.New("no report header found"))
+	}
+
+	// Report times are local to the product
+	issued, err := awips.GetIssuedTime(text)
+	if err != nil {
+		return nil, err
+	}
+	if issued.IsZero() {
+		return nil, awips.NewParseError(ComponentLSR, text, -1, errors.New("no issued time found"))
+	}
+
+	preamble := strings.ToUpper(text[:header[0]])
+	corrected := strings.Contains(preamble, "CORRECTED")
+	summary := strings.Contains(preamble, "SUMMARY")
+
+	reports := []LSR{}
+
+	lines := strings.Split(text[header[0]:], "\n")
+	offset := header[0]
+	offsets := make([]int, len(lines))
+	for i, line := range lines {
+		offsets[i] = offset
+		offset += len(line) + 1
+		lines[i] = strings.TrimRight(line, " \r")
+	}
+
+	for i := 0; i < len(lines); i++ {
+		if !lsrReportRegexp.MatchString(lines[i]) {
+			continue
+		}
+		if i+1 >= len(lines) || !lsrDateRegexp.MatchString(lines[i+1]) {
+			return nil, awips.NewParseError(ComponentLSR, text, offsets[i], errors.New("report has no date line"))
+		}
+
+		report, err := parseLSRReport(lines[i], lines[i+1], issued.Location())
+		if err != nil {
+			return nil, awips.NewParseError(ComponentLSR, text, offsets[i], err)
+		}
+		report.Corrected = corrected
+		report.Summary = summary
+
+		// The remarks are indented below the report, usually after a blank line
+		remarks := []string{}
+		j := i + 2
+		for ; j < len(lines); j++ {
+			line := lines[j]
+			if line == "" {
+				if len(remarks) > 0 {
+					break
+				}
+				continue
+			}
+			if !strings.HasPrefix(line, "            ") {
+				break
+			}
+			remarks = append(remarks, strings.TrimSpace(line))
+		}
+		report.Remarks = strings.Join(remarks, " ")
+
+		reports = append(reports, *report)
+		i = j - 1
+	}
+
+	if len(reports) == 0 {
+		return nil, awips.NewParseError(ComponentLSR, text, header[0], errors.New("no reports found"))
+	}
+
+	return reports, nil
+}
+
+func parseLSRReport(first string, second string, location *time.Location) (*LSR, error) {
+	t, err := time.ParseInLocation("0304 PM 01/02/2006", column(first, 0, 7)+" "+column(second, 0, 10), location)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse report time: %w", err)
+	}
+
+	latlon := lsrLatLonRegexp.FindStringSubmatch(column(first, 53, len(first)))
+	if latlon == nil {
+		return nil, errors.New("could not find report location")
+	}
+	lat, err := strconv.ParseFloat(latlon[1], 64)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse report latitude: %w", err)
+	}
+	lon, err := strconv.ParseFloat(latlon[3], 64)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse report longitude: %w", err)
+	}
+	if latlon[2] == "S" {
+		lat = -lat
+	}
+	if latlon[4] == "W" {
+		lon = -lon
+	}
+
+	report := LSR{
+		Time:   t,
+		Event:  column(first, 12, 29),
+		City:   column(first, 29, 53),
+		County: column(second, 29, 48),
+		State:  column(second, 48, 53),
+		Source: column(second, 53, len(second)),
+		Point: awips.PointFeature{
+			Type:        "Point",
+			Coordinates: []float64{lon, lat},
+		},
+	}
+
+	if magnitude := column(second, 12, 29); magnitude != "" {
+		match := lsrMagnitudeRegexp.FindStringSubmatch(magnitude)
+		if match == nil {
+			return nil, fmt.Errorf("could not parse magnitude %s", magnitude)
+		}
+		report.MagnitudeQualifier = match[1]
+		report.Magnitude, err = strconv.ParseFloat(match[2], 64)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse magnitude %s: %w", magnitude, err)
+		}
+		report.MagnitudeUnits = match[3]
+	}
+
+	return &report, nil
+}
+
+// The trimmed text between the columns, empty if the line is too short
+func column(line string, start int, end int) string {
+	if start >= len(line) {
+		return ""
+	}
+	end = min(end, len(line))
+	return strings.TrimSpace(line[start:end])
+}
@@ -0,0 +1,199 @@
+package products
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/metdatasystem/mds-awips/pkg/awips"
+)
+
+const testLSR = `NWUS52 KRAH 262130
+LSRRAH
+
+PRELIMINARY LOCAL STORM REPORT
+NATIONAL WEATHER SERVICE RALEIGH NC
+530 PM EDT THU JUN 26 2025
+
+..TIME...   ...EVENT...      ...CITY LOCATION...     ...LAT.LON...
+..DATE...   ....MAG....      ..COUNTY LOCATION..ST.. ...SOURCE....
+            ..REMARKS..
+
+0515 PM     TSTM WND DMG     2 NW CARY               35.80N 78.81W
+06/26/2025                   WAKE               NC   PUBLIC
+
+            SEVERAL TREES DOWN ON HOUSES ALONG
+            KILDAIRE FARM ROAD.
+
+0520 PM     HAIL             RALEIGH                 35.78N 78.64W
+06/26/2025  E1.00 INCH       WAKE               NC   TRAINED SPOTTER
+
+
+&&
+
+$$
+
+SMITH
+`
+
+const testLSRSummary = `NWUS52 KRAH 270200
+LSRRAH
+
+PRELIMINARY LOCAL STORM REPORT...SUMMARY
+NATIONAL WEATHER SERVICE RALEIGH NC
+1000 PM EDT THU JUN 26 2025
+
+..TIME...   ...EVENT...      ...CITY LOCATION...     ...LAT.LON...
+..DATE...   ....MAG....      ..COUNTY LOCATION..ST.. ...SOURCE....
+            ..REMARKS..
+
+0515 PM     TSTM WND DMG     2 NW CARY               35.80N 78.81W
+06/26/2025                   WAKE               NC   PUBLIC
+
+0520 PM     HAIL             RALEIGH                 35.78N 78.64W
+06/26/2025  E1.00 INCH       WAKE               NC   TRAINED SPOTTER
+
+0545 PM     TSTM WND GST     RDU AIRPORT             35.87N 78.79W
+06/26/2025  M58 MPH          WAKE               NC   ASOS
+
+            ASOS STATION KRDU.
+
+&&
+
+$$
+`
+
+const testLSRCorrected = `NWUS52 KRAH 262145
+LSRRAH
+
+PRELIMINARY LOCAL STORM REPORT...CORRECTED
+NATIONAL WEATHER SERVICE RALEIGH NC
+545 PM EDT THU JUN 26 2025
+
+..TIME...   ...EVENT...      ...CITY LOCATION...     ...LAT.LON...
+..DATE...   ....MAG....      ..COUNTY LOCATION..ST.. ...SOURCE....
+            ..REMARKS..
+
+0520 PM     HAIL             RALEIGH                 35.78N 78.64W
+06/26/2025  M1.75 INCH       WAKE               NC   TRAINED SPOTTER
+
+            CORRECTS HAIL SIZE.
+
+&&
+
+$$
+`
+
+func TestLSRParse(t *testing.T) {
+	reports, err := ParseLSR(testLSR)
+	if err != nil {
+		t.Fatalf("failed to parse LSR: %v", err)
+	}
+	if len(reports) != 2 {
+		t.Fatalf("expected 2 reports, got %d", len(reports))
+	}
+
+	wind := reports[0]
+	if wind.Event != "TSTM WND DMG" {
+		t.Errorf("expected event 'TSTM WND DMG', got '%s'", wind.Event)
+	}
+	if wind.City != "2 NW CARY" || wind.County != "WAKE" || wind.State != "NC" || wind.Source != "PUBLIC" {
+		t.Errorf("expected 2 NW CARY, WAKE, NC, PUBLIC, got %s, %s, %s, %s", wind.City, wind.County, wind.State, wind.Source)
+	}
+	if wind.Time.UTC().Day() != 26 || wind.Time.UTC().Hour() != 21 || wind.Time.UTC().Minute() != 15 {
+		t.Errorf("expected time 2115Z, got '%s'", wind.Time.UTC())
+	}
+	if wind.Point.Coordinates[0] != -78.81 || wind.Point.Coordinates[1] != 35.80 {
+		t.Errorf("expected point [-78.81 35.80], got %v", wind.Point.Coordinates)
+	}
+	if wind.Magnitude != 0 || wind.MagnitudeUnits != "" {
+		t.Errorf("expected no magnitude, got %f %s", wind.Magnitude, wind.MagnitudeUnits)
+	}
+	if wind.Remarks != "SEVERAL TREES DOWN ON HOUSES ALONG KILDAIRE FARM ROAD." {
+		t.Errorf("unexpected remarks '%s'", wind.Remarks)
+	}
+	if wind.Corrected || wind.Summary {
+		t.Errorf("expected report to not be corrected or a summary")
+	}
+
+	hail := reports[1]
+	if hail.Magnitude != 1 || hail.MagnitudeQualifier != "E" || hail.MagnitudeUnits != "INCH" {
+		t.Errorf("expected magnitude E1.00 INCH, got %s%f %s", hail.MagnitudeQualifier, hail.Magnitude, hail.MagnitudeUnits)
+	}
+	if hail.Source != "TRAINED SPOTTER" {
+		t.Errorf("expected source 'TRAINED SPOTTER', got '%s'", hail.Source)
+	}
+	if hail.Remarks != "" {
+		t.Errorf("expected no remarks, got '%s'", hail.Remarks)
+	}
+}
+
+func TestLSRParseSummary(t *testing.T) {
+	reports, err := ParseLSR(testLSRSummary)
+	if err != nil {
+		t.Fatalf("failed to parse LSR: %v", err)
+	}
+	if len(reports) != 3 {
+		t.Fatalf("expected 3 reports, got %d", len(reports))
+	}
+	for _, report := range reports {
+		if !report.Summary {
+			t.Errorf("expected report %s to be a summary", report.Event)
+		}
+	}
+
+	gust := reports[2]
+	if gust.Magnitude != 58 || gust.MagnitudeQualifier != "M" || gust.MagnitudeUnits != "MPH" {
+		t.Errorf("expected magnitude M58 MPH, got %s%f %s", gust.MagnitudeQualifier, gust.Magnitude, gust.MagnitudeUnits)
+	}
+	if gust.Remarks != "ASOS STATION KRDU." {
+		t.Errorf("expected remarks 'ASOS STATION KRDU.', got '%s'", gust.Remarks)
+	}
+}
+
+func TestLSRParseCorrected(t *testing.T) {
+	reports, err := ParseLSR(testLSRCorrected)
+	if err != nil {
+		t.Fatalf("failed to parse LSR: %v", err)
+	}
+	if len(reports) != 1 {
+		t.Fatalf("expected 1 report, got %d", len(reports))
+	}
+	if !reports[0].Corrected || reports[0].Summary {
+		t.Errorf("expected a corrected report that is not a summary")
+	}
+	if reports[0].Magnitude != 1.75 {
+		t.Errorf("expected magnitude 1.75, got %f", reports[0].Magnitude)
+	}
+}
+
+func TestLSRParseInvalidLocation(t *testing.T) {
+	text := `NWUS52 KRAH 262130
+LSRRAH
+
+530 PM EDT THU JUN 26 2025
+
+..TIME...   ...EVENT...      ...CITY LOCATION...     ...LAT.LON...
+
+0515 PM     TSTM WND DMG     2 NW CARY               UNKNOWN
+06/26/2025                   WAKE               NC   PUBLIC
+`
+	_, err := ParseLSR(text)
+
+	var parseErr *awips.ParseError
+	if !errors.As(err, &parseErr) || parseErr.Component != ComponentLSR {
+		t.Fatalf("expected LSR ParseError, got %v", err)
+	}
+	if parseErr.Line != 8 {
+		t.Errorf("expected error on line 8, got %d", parseErr.Line)
+	}
+}
+
+func FuzzParseLSR(f *testing.F) {
+	f.Add(testLSR)
+	f.Add(testLSRSummary)
+	f.Add(testLSRCorrected)
+	f.Fuzz(func(t *testing.T, text string) {
+		_, err := ParseLSR(text)
+		checkParseError(t, err)
+	})
+}
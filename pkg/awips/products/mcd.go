@@ -11,17 +11,26 @@ import (
 	"github.com/metdatasystem/mds-awips/pkg/awips"
 )
 
+const ComponentMCD = "mcd"
+
 type MCD struct {
 	Original         string               `json:"original"`
 	Number           int                  `json:"number"`
 	Issued           time.Time            `json:"issued"`
 	Expires          time.Time            `json:"expires"`
 	Concerning       string               `json:"concerning"`
+	Watches          []MCDWatch           `json:"watches"`
 	Polygon          awips.PolygonFeature `json:"polygon"`
 	WatchProbability int                  `json:"watch_probability"`
 	MostProbTornado  string               `json:"most_prob_tornado"`
 	MostProbGust     string               `json:"most_prob_gust"`
-	MostProbHail     string               `json:"mopst_prob_hail"`
+	MostProbHail     string               `json:"most_prob_hail"`
+}
+
+// A watch referenced in the concerning line of an MCD
+type MCDWatch struct {
+	Phenomena string `json:"phenomena"` // TO or SV as in the watch VTEC
+	Number    int    `json:"number"`
 }
 
 func ParseMCD(text string) (*MCD, error) {
@@ -32,11 +41,11 @@ func ParseMCD(text string) (*MCD, error) {
 	mcdString := mcdRegex.FindString(text)
 	numberString := valueRegexp.FindString(mcdString)
 	if numberString == "" {
-		return nil, errors.New("error parsing mcd: No MCD number found")
+		return nil, awips.NewParseError(ComponentMCD, text, -1, errors.New("no MCD number found"))
 	}
 	number, err := strconv.Atoi(numberString)
 	if err != nil {
-		return nil, fmt.Errorf("error parsing mcd number: %s", err.Error())
+		return nil, awips.NewParseError(ComponentMCD, text, strings.Index(text, mcdString), err)
 	}
 
 	validRegex := regexp.MustCompile("(Valid|VALID) ([0-9]{6}Z) - ([0-9]{6}Z)\n")
@@ -45,30 +54,39 @@ func ParseMCD(text string) (*MCD, error) {
 	times := timeRegex.FindAllString(validString, 2)
 
 	if len(times) != 2 {
-		return nil, fmt.Errorf("error parsing mcd: Invalid number of valid times. Found %d, expected 2", len(times))
+		return nil, awips.NewParseError(ComponentMCD, text, -1, fmt.Errorf("invalid number of valid times. Found %d, expected 2", len(times)))
 	}
 
 	issued, err := time.Parse("021504Z", times[0])
 	if err != nil {
-		return nil, fmt.Errorf("error parsing mcd issued time: %s", err.Error())
+		return nil, awips.NewParseError(ComponentMCD, text, strings.Index(text, validString), fmt.Errorf("could not parse issued time: %w", err))
 	}
 	expires, err := time.Parse("021504Z", times[1])
 	if err != nil {
-		return nil, fmt.Errorf("error parsing mcd expire time: %s", err.Error())
+		return nil, awips.NewParseError(ComponentMCD, text, strings.Index(text, validString), fmt.Errorf("could not parse expire time: %w", err))
 	}
 
 	concerningRegex := regexp.MustCompile(`(Concerning\.\.\.)(.+)`)
 	concerningString := concerningRegex.FindString(text)
 
 	if concerningString == "" {
-		return nil, fmt.Errorf("error parsing mcd: No concerning text found")
+		return nil, awips.NewParseError(ComponentMCD, text, -1, errors.New("no concerning text found"))
 	}
 
-	concerning := strings.ReplaceAll(concerningString, "Concerning...", "")
+	concerning := strings.TrimSpace(strings.ReplaceAll(concerningString, "Concerning...", ""))
+
+	watches, err := parseMCDWatches(concerning)
+	if err != nil {
+		return nil, awips.NewParseError(ComponentMCD, text, strings.Index(text, concerningString), fmt.Errorf("could not parse watches: %w", err))
+	}
 
 	latlon, err := awips.ParseLatLon(text)
 	if err != nil {
-		return nil, fmt.Errorf("error parsing mcd latlon: %s", err.Error())
+		return nil, err
+	}
+
+	if latlon == nil {
+		return nil, awips.NewParseError(ComponentMCD, text, -1, errors.New("no LAT...LON found"))
 	}
 
 	polygon := latlon.Polygon
@@ -80,46 +98,28 @@ func ParseMCD(text string) (*MCD, error) {
 		valueString := valueRegexp.FindString(probabilityString)
 
 		if valueString == "" {
-			return nil, fmt.Errorf("error parsing mcd: Found probability string but no numbers")
+			return nil, awips.NewParseError(ComponentMCD, text, strings.Index(text, probabilityString), errors.New("found probability string but no numbers"))
 		}
 
 		probability, err = strconv.Atoi(valueString)
 		if err != nil {
-			return nil, fmt.Errorf("error parsing mcd probability: %s", err.Error())
+			return nil, awips.NewParseError(ComponentMCD, text, strings.Index(text, probabilityString), err)
 		}
 	}
 
-	probTornadoRegexp := regexp.MustCompile(`(MOST PROBABLE PEAK TORNADO INTENSITY\.\.\.)([\w ]+)`)
-	probTornadoString := probTornadoRegexp.FindString(text)
-	var probTornado string
-	if probTornadoString != "" {
-		values := strings.Split(probTornadoString, "...")
-		if len(values) < 2 {
-			return nil, fmt.Errorf("tornado probability string was found but split returned %d elements", len(values))
-		}
-		probTornado = values[1]
+	probTornado, err := findMostProbable(text, "TORNADO INTENSITY")
+	if err != nil {
+		return nil, awips.NewParseError(ComponentMCD, text, -1, fmt.Errorf("tornado %w", err))
 	}
 
-	probGustRegexp := regexp.MustCompile(`(MOST PROBABLE PEAK TORNADO INTENSITY\.\.\.)([\w ]+)`)
-	probGustString := probGustRegexp.FindString(text)
-	var probGust string
-	if probGustString != "" {
-		values := strings.Split(probGustString, "...")
-		if len(values) < 2 {
-			return nil, fmt.Errorf("gust probability string was found but split returned %d elements", len(values))
-		}
-		probGust = values[1]
+	probGust, err := findMostProbable(text, "WIND GUST")
+	if err != nil {
+		return nil, awips.NewParseError(ComponentMCD, text, -1, fmt.Errorf("gust %w", err))
 	}
 
-	probHialRegexp := regexp.MustCompile(`(MOST PROBABLE PEAK TORNADO INTENSITY\.\.\.)([\w ]+)`)
-	probHailString := probHialRegexp.FindString(text)
-	var probHail string
-	if probHailString != "" {
-		values := strings.Split(probHailString, "...")
-		if len(values) < 2 {
-			return nil, fmt.Errorf("hail probability string was found but split returned %d elements", len(values))
-		}
-		probHail = values[1]
+	probHail, err := findMostProbable(text, "HAIL SIZE")
+	if err != nil {
+		return nil, awips.NewParseError(ComponentMCD, text, -1, fmt.Errorf("hail %w", err))
 	}
 
 	mcd := MCD{
@@ -128,6 +128,7 @@ func ParseMCD(text string) (*MCD, error) {
 		Issued:           issued,
 		Expires:          expires,
 		Concerning:       concerning,
+		Watches:          watches,
 		Polygon:          *polygon,
 		WatchProbability: probability,
 		MostProbTornado:  probTornado,
@@ -137,3 +138,45 @@ func ParseMCD(text string) (*MCD, error) {
 
 	return &mcd, nil
 }
+
+// Finds the value of a "MOST PROBABLE PEAK ..." line, e.g. MOST PROBABLE PEAK WIND GUST...65-80 MPH
+func findMostProbable(text string, name string) (string, error) {
+	probRegexp := regexp.MustCompile(`MOST PROBABLE PEAK ` + name + `\.\.\.(.+)`)
+	probString := probRegexp.FindString(text)
+	if probString == "" {
+		return "", nil
+	}
+
+	values := strings.Split(probString, "...")
+	if len(values) < 2 {
+		return "", fmt.Errorf("probability string was found but split returned %d elements", len(values))
+	}
+
+	return strings.TrimSpace(values[1]), nil
+}
+
+// Finds the watches in the concerning line, e.g. Severe Thunderstorm Watch 123...124
+func parseMCDWatches(concerning string) ([]MCDWatch, error) {
+	watchRegexp := regexp.MustCompile(`(?i)(Tornado|Severe Thunderstorm) Watch(?:es)? ([0-9]+(?:\.\.\.[0-9]+)*)`)
+
+	watches := []MCDWatch{}
+	for _, match := range watchRegexp.FindAllStringSubmatch(concerning, -1) {
+		phenomena := "SV"
+		if strings.EqualFold(match[1], "Tornado") {
+			phenomena = "TO"
+		}
+
+		for _, numberString := range strings.Split(match[2], "...") {
+			number, err := strconv.Atoi(numberString)
+			if err != nil {
+				return nil, err
+			}
+			watches = append(watches, MCDWatch{
+				Phenomena: phenomena,
+				Number:    number,
+			})
+		}
+	}
+
+	return watches, nil
+}
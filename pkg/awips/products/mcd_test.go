@@ -0,0 +1,117 @@
+package products
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/metdatasystem/mds-awips/pkg/awips"
+)
+
+const testMCD = `ACUS11 KWNS 262015
+SWOMCD
+SPC MCD 262015
+NCZ000-VAZ000-262215-
+
+Mesoscale Discussion 1450
+NWS Storm Prediction Center Norman OK
+0315 PM CDT Thu Jun 26 2025
+
+Areas affected...Central North Carolina into southern Virginia
+
+Concerning...Severe Thunderstorm Watch 456...457
+
+Valid 262015Z - 262215Z
+
+Probability of Watch Issuance...40 percent
+
+SUMMARY...Scattered damaging winds remain possible.
+
+ATTN...WFO...AKQ...RAH...
+
+LAT...LON   35637993 36937922 37117801 36397735 35557769 35257891
+            35637993
+
+MOST PROBABLE PEAK TORNADO INTENSITY...85-115 MPH
+MOST PROBABLE PEAK WIND GUST...65-80 MPH
+MOST PROBABLE PEAK HAIL SIZE...1.50-2.50 IN
+`
+
+func TestMCDParse(t *testing.T) {
+	mcd, err := ParseMCD(testMCD)
+	if err != nil {
+		t.Fatalf("failed to parse MCD: %v", err)
+	}
+
+	if mcd.Number != 1450 {
+		t.Errorf("expected number 1450, got %d", mcd.Number)
+	}
+	if mcd.Issued.Day() != 26 || mcd.Issued.Hour() != 20 || mcd.Issued.Minute() != 15 {
+		t.Errorf("expected issued 262015Z, got '%s'", mcd.Issued)
+	}
+	if mcd.Expires.Day() != 26 || mcd.Expires.Hour() != 22 || mcd.Expires.Minute() != 15 {
+		t.Errorf("expected expires 262215Z, got '%s'", mcd.Expires)
+	}
+	if mcd.Concerning != "Severe Thunderstorm Watch 456...457" {
+		t.Errorf("expected concerning 'Severe Thunderstorm Watch 456...457', got '%s'", mcd.Concerning)
+	}
+	if len(mcd.Watches) != 2 {
+		t.Fatalf("expected 2 watches, got %d", len(mcd.Watches))
+	}
+	if mcd.Watches[0].Phenomena != "SV" || mcd.Watches[0].Number != 456 {
+		t.Errorf("expected first watch SV 456, got %s %d", mcd.Watches[0].Phenomena, mcd.Watches[0].Number)
+	}
+	if mcd.Watches[1].Number != 457 {
+		t.Errorf("expected second watch 457, got %d", mcd.Watches[1].Number)
+	}
+	if mcd.WatchProbability != 40 {
+		t.Errorf("expected watch probability 40, got %d", mcd.WatchProbability)
+	}
+	if mcd.MostProbTornado != "85-115 MPH" {
+		t.Errorf("expected tornado '85-115 MPH', got '%s'", mcd.MostProbTornado)
+	}
+	if mcd.MostProbGust != "65-80 MPH" {
+		t.Errorf("expected gust '65-80 MPH', got '%s'", mcd.MostProbGust)
+	}
+	if mcd.MostProbHail != "1.50-2.50 IN" {
+		t.Errorf("expected hail '1.50-2.50 IN', got '%s'", mcd.MostProbHail)
+	}
+	if len(mcd.Polygon.Coordinates) != 1 || len(mcd.Polygon.Coordinates[0]) != 7 {
+		t.Errorf("expected polygon with 7 points, got %v", mcd.Polygon.Coordinates)
+	}
+}
+
+func TestMCDParseNoWatch(t *testing.T) {
+	mcd, err := ParseMCD(`Mesoscale Discussion 0012
+Concerning...Heavy snow
+
+Valid 021200Z - 021600Z
+
+LAT...LON   40009000 41009000 41008900 40008900
+`)
+	if err != nil {
+		t.Fatalf("failed to parse MCD: %v", err)
+	}
+
+	if len(mcd.Watches) != 0 {
+		t.Errorf("expected no watches, got %d", len(mcd.Watches))
+	}
+	if mcd.MostProbGust != "" {
+		t.Errorf("expected no gust, got '%s'", mcd.MostProbGust)
+	}
+}
+
+func FuzzParseMCD(f *testing.F) {
+	f.Add(testMCD)
+	f.Add("Mesoscale Discussion 0012\nConcerning...Tornado Watch 99999999999999999999\nValid 021200Z - 021600Z\n")
+	f.Add("Mesoscale Discussion 0012\nConcerning...Heavy snow\nValid 021200Z - 021600Z\nLAT...LON 4000900\n")
+	f.Fuzz(func(t *testing.T, text string) {
+		_, err := ParseMCD(text)
+		if err == nil {
+			return
+		}
+		var parseErr *awips.ParseError
+		if !errors.As(err, &parseErr) {
+			t.Errorf("expected a ParseError, got %T: %v", err, err)
+		}
+	})
+}
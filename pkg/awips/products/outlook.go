@@ -0,0 +1,232 @@
+package products
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/metdatasystem/mds-awips/pkg/awips"
+)
+
+const ComponentOutlook = "outlook"
+
+// An SPC convective outlook decoded from the points products PTSDY1, PTSDY2, PTSDY3 and PTSD48
+type Outlook struct {
+	Original string        `json:"original"`
+	Day      int           `json:"day"`     // The first day of the outlook, 4 for the day 4-8 outlook
+	Issued   time.Time     `json:"issued"`  // Only day, hour, minute
+	Expires  time.Time     `json:"expires"` // Only day, hour, minute
+	Areas    []OutlookArea `json:"areas"`
+}
+
+// The area of one threshold of an outlook, e.g. the 15% hail probability or the slight risk
+type OutlookArea struct {
+	Day       int                       `json:"day"`
+	Type      string                    `json:"type"`      // CATEGORICAL, TORNADO, HAIL, WIND or ANY SEVERE
+	Threshold string                    `json:"threshold"` // A category such as SLGT, a probability such as 0.15, or SIGN for hatching
+	Polygon   awips.MultiPolygonFeature `json:"polygon"`
+}
+
+var (
+	outlookDayRegexp       = regexp.MustCompile(`DAY ([1-8])(?:-8)? CONVECTIVE OUTLOOK`)
+	outlookPointsDayRegexp = regexp.MustCompile(`POINTS DAY ([1-8])`)
+	outlookValidRegexp     = regexp.MustCompile(`VALID TIME ([0-9]{6}Z) - ([0-9]{6}Z)`)
+	outlookSectionRegexp   = regexp.MustCompile(`^\.\.\. (.+) \.\.\.$`)
+	outlookThresholdRegexp = regexp.MustCompile(`^([A-Z0-9.]+)\s+((?:[0-9]{5,8}\s*)+)$`)
+	outlookPointsRegexp    = regexp.MustCompile(`^\s+((?:[0-9]{5,8}\s*)+)$`)
+)
+
+// The SPC separates the lines of a threshold with this instead of a point
+const outlookBreak = "99999"
+
+/*
+Parses an SPC outlook points product. Each threshold is a list of lines separated by 99999. A line that ends where it
+started is a closed area, otherwise the area is to the right of the line and is closed along the CONUS outline.
+A closed line drawn counter-clockwise excludes an area, such as a hole in general thunderstorms.
+*/
+func ParsePTS(text string) (*Outlook, error) {
+	outlook := Outlook{
+		Original: text,
+		Areas:    []OutlookArea{},
+	}
+
+	if match := outlookDayRegexp.FindStringSubmatch(text); match != nil {
+		outlook.Day, _ = strconv.Atoi(match[1])
+	} else {
+		return nil, awips.NewParseError(ComponentOutlook, text, -1, errors.New("could not find outlook day"))
+	}
+
+	valid := outlookValidRegexp.FindStringSubmatchIndex(text)
+	if valid == nil {
+		return nil, awips.NewParseError(ComponentOutlook, text, -1, errors.New("could not find valid time"))
+	}
+	var err error
+	outlook.Issued, err = time.Parse("021504Z", text[valid[2]:valid[3]])
+	if err != nil {
+		return nil, awips.NewParseError(ComponentOutlook, text, valid[0], fmt.Errorf("could not parse valid time: %w", err))
+	}
+	outlook.Expires, err = time.Parse("021504Z", text[valid[4]:valid[5]])
+	if err != nil {
+		return nil, awips.NewParseError(ComponentOutlook, text, valid[0], fmt.Errorf("could not parse expire time: %w", err))
+	}
+
+	type threshold struct {
+		day       int
+		kind      string
+		threshold string
+		offset    int // Where the threshold was first found
+		points    []string
+	}
+
+	thresholds := []*threshold{}
+	var current *threshold
+	day := outlook.Day
+	kind := ""
+
+	// Thresholds can be listed more than once in a section, each is added to the same area
+	find := func(name string, offset int) *threshold {
+		for _, t := range thresholds {
+			if t.day == day && t.kind == kind && t.threshold == name {
+				t.points = append(t.points, outlookBreak)
+				return t
+			}
+		}
+		t := &threshold{day: day, kind: kind, threshold: name, offset: offset}
+		thresholds = append(thresholds, t)
+		return t
+	}
+
+	offset := 0
+	for _, line := range strings.Split(text, "\n") {
+		lineOffset := offset
+		offset += len(line) + 1
+		line = strings.TrimRight(line, " \r")
+
+		if match := outlookPointsDayRegexp.FindStringSubmatch(line); match != nil {
+			day, _ = strconv.Atoi(match[1])
+			continue
+		}
+		if match := outlookSectionRegexp.FindStringSubmatch(line); match != nil {
+			kind = strings.TrimSpace(match[1])
+			current = nil
+			continue
+		}
+		if line == "&&" {
+			kind = ""
+			current = nil
+			continue
+		}
+		if kind == "" {
+			continue
+		}
+
+		if match := outlookThresholdRegexp.FindStringSubmatch(line); match != nil {
+			current = find(match[1], lineOffset)
+			current.points = append(current.points, strings.Fields(match[2])...)
+			continue
+		}
+		if match := outlookPointsRegexp.FindStringSubmatch(line); match != nil && current != nil {
+			current.points = append(current.points, strings.Fields(match[1])...)
+		}
+	}
+
+	for _, t := range thresholds {
+		polygon, err := outlookPolygon(t.points)
+		if err != nil {
+			return nil, awips.NewParseError(ComponentOutlook, text, t.offset, err)
+		}
+		if len(polygon.Coordinates) == 0 {
+			continue
+		}
+
+		outlook.Areas = append(outlook.Areas, OutlookArea{
+			Day:       t.day,
+			Type:      t.kind,
+			Threshold: t.threshold,
+			Polygon:   polygon,
+		})
+	}
+
+	return &outlook, nil
+}
+
+// Build the polygons of a threshold from its points
+func outlookPolygon(points []string) (awips.MultiPolygonFeature, error) {
+	exteriors := [][][]float64{}
+	holes := [][][]float64{}
+
+	line := [][]float64{}
+	for i := 0; i <= len(points); i++ {
+		if i < len(points) && points[i] != outlookBreak {
+			point, err := awips.ParsePoint([]string{points[i]})
+			if err != nil {
+				return awips.MultiPolygonFeature{}, err
+			}
+			line = append(line, *point)
+			continue
+		}
+
+		// The end of a line
+		if len(line) >= 2 {
+			first, last := line[0], line[len(line)-1]
+			closed := math.Abs(first[0]-last[0]) < 0.01 && math.Abs(first[1]-last[1]) < 0.01
+			switch {
+			case closed && len(line) < 4:
+				// Not enough points for an area
+			case closed && signedArea(line) > 0:
+				holes = append(holes, line)
+			case closed:
+				exteriors = append(exteriors, line)
+			default:
+				if ring := closeLine(line); len(ring) >= 4 {
+					exteriors = append(exteriors, ring)
+				}
+			}
+		}
+		line = [][]float64{}
+	}
+
+	polygons := [][][][]float64{}
+	for _, exterior := range exteriors {
+		polygons = append(polygons, [][][]float64{exterior})
+	}
+
+	// Holes are cut from the area that contains them, or from all of CONUS if there is none
+	for _, hole := range holes {
+		found := false
+		for i, polygon := range polygons {
+			if inRing(hole[0], polygon[0]) {
+				polygons[i] = append(polygons[i], hole)
+				found = true
+				break
+			}
+		}
+		if !found {
+			exterior := append(append([][]float64{}, conus...), conus[0])
+			polygons = append(polygons, [][][]float64{exterior, hole})
+		}
+	}
+
+	// Every area is limited to CONUS, including closed lines that extend offshore or over the borders
+	clipped := [][][][]float64{}
+	for _, polygon := range polygons {
+		clipped = append(clipped, clipPolygon(polygon)...)
+	}
+	polygons = clipped
+
+	// GeoJSON exteriors are counter-clockwise and holes are clockwise, the opposite of how they are drawn
+	for _, polygon := range polygons {
+		for i, ring := range polygon {
+			polygon[i] = reverse(ring)
+		}
+	}
+
+	return awips.MultiPolygonFeature{
+		Type:        "MultiPolygon",
+		Coordinates: polygons,
+	}, nil
+}
@@ -0,0 +1,221 @@
+package products
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/metdatasystem/mds-awips/pkg/awips"
+)
+
+const testPTS = `WUUS01 KWNS 261630
+PTSDY1
+
+DAY 1 CONVECTIVE OUTLOOK AREAL OUTLINE
+NWS STORM PREDICTION CENTER NORMAN OK
+1130 AM CDT THU JUN 26 2025
+
+VALID TIME 261630Z - 271200Z
+
+PROBABILISTIC OUTLOOK POINTS DAY 1 CONVECTIVE OUTLOOK AT 1630Z
+
+... TORNADO ...
+
+0.02   40009500 40009000 38009000 38009500 40009500
+
+&&
+
+... HAIL ...
+
+0.05   40009500 40009000 38009000 38009500
+       40009500
+SIGN   39509400 39509200 38509200 38509400 39509400
+
+&&
+
+CATEGORICAL OUTLOOK POINTS DAY 1 CONVECTIVE OUTLOOK AT 1630Z
+
+... CATEGORICAL ...
+
+SLGT   40009500 40009000 38009000 38009500 40009500
+TSTM   50009500 28009500 99999 42000000 42000200 40000200 42000000
+
+&&
+
+THERE IS A SLGT RISK OF SVR TSTMS TO THE RIGHT OF A LINE FROM
+`
+
+func TestPTSParse(t *testing.T) {
+	outlook, err := ParsePTS(testPTS)
+	if err != nil {
+		t.Fatalf("failed to parse outlook: %v", err)
+	}
+
+	if outlook.Day != 1 {
+		t.Errorf("expected day 1, got %d", outlook.Day)
+	}
+	if outlook.Issued.Day() != 26 || outlook.Issued.Hour() != 16 || outlook.Issued.Minute() != 30 {
+		t.Errorf("expected issued 261630Z, got '%s'", outlook.Issued)
+	}
+	if outlook.Expires.Day() != 27 || outlook.Expires.Hour() != 12 {
+		t.Errorf("expected expires 271200Z, got '%s'", outlook.Expires)
+	}
+
+	expected := []struct {
+		kind      string
+		threshold string
+	}{
+		{"TORNADO", "0.02"},
+		{"HAIL", "0.05"},
+		{"HAIL", "SIGN"},
+		{"CATEGORICAL", "SLGT"},
+		{"CATEGORICAL", "TSTM"},
+	}
+	if len(outlook.Areas) != len(expected) {
+		t.Fatalf("expected %d areas, got %d", len(expected), len(outlook.Areas))
+	}
+	for i, e := range expected {
+		area := outlook.Areas[i]
+		if area.Type != e.kind || area.Threshold != e.threshold {
+			t.Errorf("expected area %d to be %s %s, got %s %s", i, e.kind, e.threshold, area.Type, area.Threshold)
+		}
+		if area.Polygon.Type != "MultiPolygon" {
+			t.Errorf("expected a MultiPolygon, got '%s'", area.Polygon.Type)
+		}
+	}
+
+	// A closed area is kept as drawn but counter-clockwise
+	hail := outlook.Areas[1].Polygon.Coordinates
+	if len(hail) != 1 || len(hail[0]) != 1 || len(hail[0][0]) != 5 {
+		t.Fatalf("expected a single 5 point ring, got %v", hail)
+	}
+	if signedArea(hail[0][0]) <= 0 {
+		t.Errorf("expected the exterior to be counter-clockwise")
+	}
+
+	// The open line runs south along 95W so thunderstorms are to the west, with a hole drawn counter-clockwise
+	tstm := outlook.Areas[4].Polygon.Coordinates
+	if len(tstm) != 1 || len(tstm[0]) != 2 {
+		t.Fatalf("expected one polygon with a hole, got %d polygons", len(tstm))
+	}
+	if !inRing([]float64{-100, 40}, tstm[0][0]) {
+		t.Errorf("expected thunderstorms in Kansas")
+	}
+	if inRing([]float64{-90, 40}, tstm[0][0]) {
+		t.Errorf("expected no thunderstorms in Illinois")
+	}
+	if inRing([]float64{-130, 40}, tstm[0][0]) {
+		t.Errorf("expected thunderstorms to be clipped to CONUS")
+	}
+	if !inRing([]float64{-101.5, 41.5}, tstm[0][1]) || signedArea(tstm[0][1]) >= 0 {
+		t.Errorf("expected a clockwise hole in Nebraska")
+	}
+}
+
+func TestPTSParseCrossesBoundary(t *testing.T) {
+	outlook, err := ParsePTS(`DAY 4-8 CONVECTIVE OUTLOOK AREAL OUTLINE
+VALID TIME 291200Z - 031200Z
+
+SEVERE WEATHER OUTLOOK POINTS DAY 5
+
+... ANY SEVERE ...
+
+0.15   27009800 35009800 35008800 27008800
+
+&&
+`)
+	if err != nil {
+		t.Fatalf("failed to parse outlook: %v", err)
+	}
+
+	if len(outlook.Areas) != 1 {
+		t.Fatalf("expected 1 area, got %d", len(outlook.Areas))
+	}
+	area := outlook.Areas[0]
+	if area.Day != 5 || area.Type != "ANY SEVERE" {
+		t.Errorf("expected day 5 ANY SEVERE, got day %d %s", area.Day, area.Type)
+	}
+
+	// The line starts and ends in the Gulf so it is clipped and closed along the coast
+	ring := area.Polygon.Coordinates[0][0]
+	if !inRing([]float64{-93, 32}, ring) {
+		t.Errorf("expected Louisiana to be in the area")
+	}
+	if inRing([]float64{-93, 28}, ring) {
+		t.Errorf("expected the Gulf to be clipped")
+	}
+	if inRing([]float64{-100, 40}, ring) {
+		t.Errorf("expected Kansas to be outside the area")
+	}
+}
+
+func TestPTSParseOffshoreRing(t *testing.T) {
+	outlook, err := ParsePTS(`DAY 1 CONVECTIVE OUTLOOK AREAL OUTLINE
+VALID TIME 261630Z - 271200Z
+
+... CATEGORICAL ...
+
+SLGT   36007800 36007300 33007300 33007800 36007800
+TSTM   30006500 30006000 25006000 25006500 30006500
+
+&&
+`)
+	if err != nil {
+		t.Fatalf("failed to parse outlook: %v", err)
+	}
+
+	// The thunderstorm area is entirely over the Atlantic
+	if len(outlook.Areas) != 1 {
+		t.Fatalf("expected 1 area, got %d", len(outlook.Areas))
+	}
+
+	// The ring is already closed but still clipped along the coast
+	polygons := outlook.Areas[0].Polygon.Coordinates
+	if len(polygons) != 1 {
+		t.Fatalf("expected 1 polygon, got %d", len(polygons))
+	}
+	ring := polygons[0][0]
+	if !inRing([]float64{-77, 35.5}, ring) {
+		t.Errorf("expected eastern North Carolina to be in the area")
+	}
+	if inRing([]float64{-74, 35}, ring) {
+		t.Errorf("expected the Atlantic to be clipped")
+	}
+	if signedArea(ring) <= 0 {
+		t.Errorf("expected a counter-clockwise exterior")
+	}
+	outline := closeRing(conus)
+	for _, p := range ring {
+		if !inRing(p, outline) && distanceToOutline(p) > 1e-6 {
+			t.Errorf("expected %v to be inside CONUS", p)
+		}
+	}
+}
+
+func TestPTSParseInvalidPoint(t *testing.T) {
+	_, err := ParsePTS(`DAY 1 CONVECTIVE OUTLOOK AREAL OUTLINE
+VALID TIME 261630Z - 271200Z
+
+... CATEGORICAL ...
+
+SLGT   400095 40009000 38009000
+
+&&
+`)
+	if err == nil {
+		t.Errorf("expected an error for an invalid point")
+	}
+}
+
+func FuzzParsePTS(f *testing.F) {
+	f.Add(testPTS)
+	f.Fuzz(func(t *testing.T, text string) {
+		_, err := ParsePTS(text)
+		if err == nil {
+			return
+		}
+		var parseErr *awips.ParseError
+		if !errors.As(err, &parseErr) {
+			t.Errorf("expected a ParseError, got %T: %v", err, err)
+		}
+	})
+}
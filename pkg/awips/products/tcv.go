@@ -0,0 +1,177 @@
+package products
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/metdatasystem/mds-awips/pkg/awips"
+)
+
+const ComponentTCV = "tcv"
+
+// A Tropical Cyclone Watch/Warning product (TCV), the threats to each zone of a WFO
+type TCV struct {
+	Original string    `json:"original"`
+	Zones    []TCVZone `json:"zones"`
+}
+
+// A segment of a TCV, the threats to the zones in its UGC
+type TCVZone struct {
+	UGC     []string     `json:"ugc"` // e.g. NCZ203
+	VTEC    []awips.VTEC `json:"vtec"`
+	Hazards []TCVHazard  `json:"hazards"`
+}
+
+// The assessment of a single hazard to a zone
+type TCVHazard struct {
+	Hazard        string   `json:"hazard"`         // WIND, STORM SURGE, FLOODING RAIN or TORNADO
+	Forecast      []string `json:"forecast"`       // The latest local forecast
+	Threat        string   `json:"threat"`         // e.g. Potential for wind greater than 110 mph
+	Level         string   `json:"level"`          // Extreme, High, Moderate, Elevated or None, empty if the impacts are not one of these
+	Impacts       string   `json:"impacts"`        // e.g. Devastating to Catastrophic
+	ImpactDetails []string `json:"impact_details"` // The potential impacts
+}
+
+// The threat level of each potential impact
+var tcvLevels = []struct {
+	impacts string
+	level   string
+}{
+	{"DEVASTATING", "Extreme"},
+	{"CATASTROPHIC", "Extreme"},
+	{"EXTENSIVE", "High"},
+	{"SIGNIFICANT", "Moderate"},
+	{"LIMITED", "Elevated"},
+	{"LITTLE TO NONE", "None"},
+	{"NONE", "None"},
+}
+
+var (
+	tcvHazardRegexp = regexp.MustCompile(`(?m)^\* (WIND|STORM SURGE|FLOODING RAIN|TORNADO):`)
+	tcvSectionEnd   = regexp.MustCompile(`(?m)^\* `)
+	tcvItemRegexp   = regexp.MustCompile(`^( *)- (.*)$`)
+)
+
+/*
+Parses the hazards of each zone in a TCV. Each hazard, e.g. "* WIND:", is a list of items such as LATEST LOCAL FORECAST,
+THREAT TO LIFE AND PROPERTY and POTENTIAL IMPACTS, each followed by more indented details.
+*/
+func ParseTCV(text string) (*TCV, error) {
+	tcv := TCV{
+		Original: text,
+		Zones:    []TCVZone{},
+	}
+
+	for _, segment := range strings.Split(text, "$$") {
+		ugc, err := awips.ParseUGC(segment)
+		if err != nil {
+			return nil, err
+		}
+		if ugc == nil {
+			continue
+		}
+
+		vtecs, errs := awips.ParseVTEC(segment)
+		if len(errs) > 0 {
+			return nil, errs[0]
+		}
+		if vtecs == nil {
+			vtecs = []awips.VTEC{}
+		}
+
+		zone := TCVZone{
+			UGC:     ugc.Codes(),
+			VTEC:    vtecs,
+			Hazards: []TCVHazard{},
+		}
+
+		for _, match := range tcvHazardRegexp.FindAllStringSubmatchIndex(segment, -1) {
+			section := segment[match[1]:]
+			if end := tcvSectionEnd.FindStringIndex(section); end != nil {
+				section = section[:end[0]]
+			}
+			zone.Hazards = append(zone.Hazards, parseTCVHazard(segment[match[2]:match[3]], section))
+		}
+
+		tcv.Zones = append(tcv.Zones, zone)
+	}
+
+	if len(tcv.Zones) == 0 {
+		return nil, awips.NewParseError(ComponentTCV, text, -1, errors.New("no zones found"))
+	}
+
+	return &tcv, nil
+}
+
+func parseTCVHazard(name string, section string) TCVHazard {
+	hazard := TCVHazard{
+		Hazard:        name,
+		Forecast:      []string{},
+		ImpactDetails: []string{},
+	}
+
+	type item struct {
+		text    string
+		details []string
+	}
+
+	// Items are the least indented, anything more indented is a detail of the item before it
+	items := []*item{}
+	indent := -1
+	var last *string
+	for _, line := range strings.Split(section, "\n") {
+		line = strings.TrimRight(line, " \r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		match := tcvItemRegexp.FindStringSubmatch(line)
+		if match == nil {
+			// A continuation of the last item or detail
+			if last != nil {
+				*last += " " + strings.TrimSpace(line)
+			}
+			continue
+		}
+
+		if indent == -1 || len(match[1]) <= indent {
+			indent = len(match[1])
+			items = append(items, &item{text: match[2], details: []string{}})
+			last = &items[len(items)-1].text
+		} else if len(items) > 0 {
+			current := items[len(items)-1]
+			current.details = append(current.details, match[2])
+			last = &current.details[len(current.details)-1]
+		}
+	}
+
+	for _, i := range items {
+		heading, value, _ := strings.Cut(i.text, ":")
+		heading = strings.ToUpper(heading)
+		value = strings.TrimSpace(value)
+
+		switch {
+		case strings.HasPrefix(heading, "LATEST LOCAL FORECAST"):
+			if value != "" {
+				hazard.Forecast = append(hazard.Forecast, value)
+			}
+			hazard.Forecast = append(hazard.Forecast, i.details...)
+		case strings.HasPrefix(heading, "THREAT TO LIFE AND PROPERTY"):
+			hazard.Threat = value
+		case strings.HasPrefix(heading, "POTENTIAL IMPACTS"):
+			hazard.Impacts = value
+			hazard.ImpactDetails = append(hazard.ImpactDetails, i.details...)
+		}
+	}
+
+	impacts := strings.ToUpper(hazard.Impacts)
+	for _, l := range tcvLevels {
+		if strings.HasPrefix(impacts, l.impacts) {
+			hazard.Level = l.level
+			break
+		}
+	}
+
+	return hazard
+}
@@ -0,0 +1,170 @@
+package products
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/metdatasystem/mds-awips/pkg/awips"
+)
+
+const testTCV = `WTUS82 KMHX 211514
+TCVMHX
+
+URGENT - IMMEDIATE BROADCAST REQUESTED
+Hurricane Erin Local Watch/Warning Statement/Advisory Number 20
+National Weather Service Newport/Morehead City NC  AL052025
+1114 AM EDT Thu Aug 21 2025
+
+NCZ203-211600-
+/O.CON.KMHX.HU.W.1005.000000T0000Z-000000T0000Z/
+/O.CON.KMHX.SS.W.1005.000000T0000Z-000000T0000Z/
+Outer Banks Dare-
+1114 AM EDT Thu Aug 21 2025
+
+...HURRICANE WARNING AND STORM SURGE WARNING REMAIN IN EFFECT...
+
+* WIND:
+    - LATEST LOCAL FORECAST: Equivalent Tropical Storm force wind
+        - Peak Wind Forecast: 45-55 mph with gusts to 70 mph
+        - Window for Tropical Storm force winds: Thursday afternoon
+          until Friday morning
+
+    - THREAT TO LIFE AND PROPERTY THAT INCLUDES TYPICAL FORECAST
+      UNCERTAINTY IN TRACK, SIZE AND INTENSITY: Potential for wind 74 to 110 mph
+        - The wind threat has remained nearly steady from the
+          previous assessment.
+        - PLAN: Plan for dangerous wind of equivalent CAT 1 or 2
+          hurricane force.
+
+    - POTENTIAL IMPACTS: Extensive
+        - Considerable roof damage to sturdy buildings.
+        - Many large trees snapped or uprooted.
+
+* STORM SURGE:
+    - LATEST LOCAL FORECAST: Life-threatening storm surge possible
+        - Peak Storm Surge Inundation: The potential for 2-4 feet
+          above ground somewhere within surge prone areas
+
+    - THREAT TO LIFE AND PROPERTY THAT INCLUDES TYPICAL FORECAST
+      UNCERTAINTY IN TRACK, SIZE AND INTENSITY: Potential for storm surge flooding greater than 3 feet above ground
+
+    - POTENTIAL IMPACTS: Significant
+        - Areas of inundation with storm surge flooding.
+
+* FLOODING RAIN:
+    - LATEST LOCAL FORECAST:
+        - Peak Rainfall Amounts: Additional 1-2 inches
+
+    - THREAT TO LIFE AND PROPERTY THAT INCLUDES TYPICAL FORECAST
+      UNCERTAINTY IN TRACK, SIZE AND INTENSITY: Potential for localized flooding rain
+
+    - POTENTIAL IMPACTS: Limited
+        - Localized rainfall flooding may prompt a few evacuations.
+
+* TORNADO:
+    - LATEST LOCAL FORECAST:
+        - Situation is unfavorable for tornadoes
+
+    - THREAT TO LIFE AND PROPERTY THAT INCLUDES TYPICAL FORECAST
+      UNCERTAINTY IN TRACK, SIZE AND INTENSITY: Tornadoes not expected
+
+    - POTENTIAL IMPACTS: Little to None
+        - Little to no potential impacts from tornadoes.
+
+* FOR MORE INFORMATION:
+    - https://ready.gov/hurricanes
+
+$$
+
+NCZ196-211600-
+/O.CON.KMHX.TR.W.1005.000000T0000Z-000000T0000Z/
+Carteret-
+1114 AM EDT Thu Aug 21 2025
+
+* WIND:
+    - LATEST LOCAL FORECAST: Below tropical storm force wind
+
+    - THREAT TO LIFE AND PROPERTY THAT INCLUDES TYPICAL FORECAST
+      UNCERTAINTY IN TRACK, SIZE AND INTENSITY: Potential for wind 39 to 57 mph
+
+    - POTENTIAL IMPACTS: Limited
+
+$$
+`
+
+func TestTCVParse(t *testing.T) {
+	tcv, err := ParseTCV(testTCV)
+	if err != nil {
+		t.Fatalf("failed to parse TCV: %v", err)
+	}
+	if len(tcv.Zones) != 2 {
+		t.Fatalf("expected 2 zones, got %d", len(tcv.Zones))
+	}
+
+	zone := tcv.Zones[0]
+	if len(zone.UGC) != 1 || zone.UGC[0] != "NCZ203" {
+		t.Errorf("expected UGC [NCZ203], got %v", zone.UGC)
+	}
+	if len(zone.VTEC) != 2 || zone.VTEC[0].Phenomena != "HU" || zone.VTEC[1].Phenomena != "SS" {
+		t.Errorf("expected HU and SS VTEC, got %v", zone.VTEC)
+	}
+
+	expected := []struct {
+		hazard string
+		level  string
+	}{
+		{"WIND", "High"},
+		{"STORM SURGE", "Moderate"},
+		{"FLOODING RAIN", "Elevated"},
+		{"TORNADO", "None"},
+	}
+	if len(zone.Hazards) != len(expected) {
+		t.Fatalf("expected %d hazards, got %d", len(expected), len(zone.Hazards))
+	}
+	for i, e := range expected {
+		if zone.Hazards[i].Hazard != e.hazard || zone.Hazards[i].Level != e.level {
+			t.Errorf("expected %s %s, got %s %s", e.hazard, e.level, zone.Hazards[i].Hazard, zone.Hazards[i].Level)
+		}
+	}
+
+	wind := zone.Hazards[0]
+	if wind.Threat != "Potential for wind 74 to 110 mph" {
+		t.Errorf("unexpected wind threat '%s'", wind.Threat)
+	}
+	if wind.Impacts != "Extensive" {
+		t.Errorf("expected wind impacts 'Extensive', got '%s'", wind.Impacts)
+	}
+	if len(wind.Forecast) != 3 || wind.Forecast[2] != "Window for Tropical Storm force winds: Thursday afternoon until Friday morning" {
+		t.Errorf("unexpected wind forecast %q", wind.Forecast)
+	}
+	if len(wind.ImpactDetails) != 2 || wind.ImpactDetails[1] != "Many large trees snapped or uprooted." {
+		t.Errorf("unexpected wind impact details %q", wind.ImpactDetails)
+	}
+
+	rain := zone.Hazards[2]
+	if len(rain.Forecast) != 1 || rain.Forecast[0] != "Peak Rainfall Amounts: Additional 1-2 inches" {
+		t.Errorf("unexpected rain forecast %q", rain.Forecast)
+	}
+
+	carteret := tcv.Zones[1]
+	if len(carteret.Hazards) != 1 || carteret.Hazards[0].Level != "Elevated" || len(carteret.Hazards[0].ImpactDetails) != 0 {
+		t.Errorf("unexpected Carteret hazards %+v", carteret.Hazards)
+	}
+}
+
+func TestTCVParseNoZones(t *testing.T) {
+	_, err := ParseTCV("WTUS82 KMHX 211514\nTCVMHX\n")
+
+	var parseErr *awips.ParseError
+	if !errors.As(err, &parseErr) || parseErr.Component != ComponentTCV {
+		t.Fatalf("expected TCV ParseError, got %v", err)
+	}
+}
+
+func FuzzParseTCV(f *testing.F) {
+	f.Add(testTCV)
+	f.Fuzz(func(t *testing.T, text string) {
+		_, err := ParseTCV(text)
+		checkParseError(t, err)
+	})
+}
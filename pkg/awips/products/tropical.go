@@ -0,0 +1,319 @@
+package products
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/metdatasystem/mds-awips/pkg/awips"
+)
+
+const (
+	ComponentTCM = "tcm"
+	ComponentTCP = "tcp"
+)
+
+// An NHC or CPHC Forecast/Advisory (TCM). Speeds are in knots and distances in nautical miles.
+type TCM struct {
+	Original string                   `json:"original"`
+	StormID  string                   `json:"storm_id"` // e.g. AL052025
+	Type     string                   `json:"type"`     // e.g. HURRICANE or TROPICAL STORM
+	Name     string                   `json:"name"`     // e.g. ERIN or FIVE
+	Advisory string                   `json:"advisory"` // e.g. 20
+	Issued   time.Time                `json:"issued"`
+	Time     time.Time                `json:"time"` // Of the position, only day, hour, minute
+	Position []float64                `json:"position"`
+	Movement TropicalMovement         `json:"movement"`
+	Pressure int                      `json:"pressure"` // Millibars
+	MaxWind  int                      `json:"max_wind"`
+	Gusts    int                      `json:"gusts"`
+	Radii    []WindRadii              `json:"radii"`
+	Forecast []TropicalForecast       `json:"forecast"`
+	Track    *awips.LineStringFeature `json:"track,omitempty"` // The current position followed by the forecast positions, when there are any
+}
+
+// An NHC or CPHC Public Advisory (TCP). Speeds are in miles per hour.
+type TCP struct {
+	Original string           `json:"original"`
+	StormID  string           `json:"storm_id"`
+	Type     string           `json:"type"`
+	Name     string           `json:"name"`
+	Advisory string           `json:"advisory"` // e.g. 20 or 20A for an intermediate advisory
+	Issued   time.Time        `json:"issued"`
+	Position []float64        `json:"position"`
+	Movement TropicalMovement `json:"movement"`
+	Pressure int              `json:"pressure"` // Millibars
+	MaxWind  int              `json:"max_wind"`
+}
+
+type TropicalMovement struct {
+	Stationary bool `json:"stationary"`
+	Direction  int  `json:"direction"` // Degrees toward
+	Speed      int  `json:"speed"`
+}
+
+// The largest distance of a wind speed from the center in each quadrant
+type WindRadii struct {
+	Speed int `json:"speed"` // 34, 50 or 64
+	NE    int `json:"ne"`
+	SE    int `json:"se"`
+	SW    int `json:"sw"`
+	NW    int `json:"nw"`
+}
+
+type TropicalForecast struct {
+	Time     time.Time   `json:"time"`     // Only day, hour, minute
+	Position []float64   `json:"position"` // Empty if the storm is forecast to have dissipated
+	Status   string      `json:"status"`   // e.g. INLAND, POST-TROP/EXTRATROP or DISSIPATED
+	MaxWind  int         `json:"max_wind"`
+	Gusts    int         `json:"gusts"`
+	Radii    []WindRadii `json:"radii"`
+}
+
+// Longest first so that a type is not matched by the start of another
+var tropicalTypes = []string{
+	"POTENTIAL TROPICAL CYCLONE",
+	"POST-TROPICAL CYCLONE",
+	"SUBTROPICAL DEPRESSION",
+	"SUBTROPICAL STORM",
+	"TROPICAL DEPRESSION",
+	"TROPICAL STORM",
+	"REMNANTS OF",
+	"HURRICANE",
+	"TYPHOON",
+}
+
+var (
+	tropicalTitleRegexp   = regexp.MustCompile(`(?im)^(.+?) (?:FORECAST/|INTERMEDIATE |SPECIAL )*ADVISORY NUMBER\s+([0-9]+[A-Z]?)\s*$`)
+	tropicalStormIDRegexp = regexp.MustCompile(`\b((?:AL|EP|CP)[0-9]{6})\b`)
+	tropicalRadiiRegexp   = regexp.MustCompile(`(?m)^([0-9]{2}) KT\.+\s*([0-9]+)NE\s+([0-9]+)SE\s+([0-9]+)SW\s+([0-9]+)NW`)
+
+	tcmCenterRegexp   = regexp.MustCompile(`CENTER LOCATED NEAR\s+([0-9.]+[NS]\s+[0-9.]+[EW]) AT ([0-9]{2}/[0-9]{4}Z)`)
+	tcmMovementRegexp = regexp.MustCompile(`(?m)^PRESENT MOVEMENT.*?([0-9]+) DEGREES AT\s+([0-9]+) KT`)
+	tcmPressureRegexp = regexp.MustCompile(`MINIMUM CENTRAL PRESSURE\s+([0-9]+) MB`)
+	tcmWindRegexp     = regexp.MustCompile(`MAX SUSTAINED WINDS\s+([0-9]+) KT WITH GUSTS TO\s+([0-9]+) KT`)
+	tcmForecastRegexp = regexp.MustCompile(`(?m)^(?:FORECAST|OUTLOOK) VALID ([0-9]{2}/[0-9]{4}Z)(?:\s+([0-9.]+[NS]\s+[0-9.]+[EW]))?(?:\.\.\.(.+))?$`)
+	tcmMaxWindRegexp  = regexp.MustCompile(`MAX WIND\s+([0-9]+) KT\.\.\.GUSTS\s+([0-9]+) KT`)
+
+	tcpLocationRegexp = regexp.MustCompile(`(?i)LOCATION\.\.\.([0-9.]+[NS]\s+[0-9.]+[EW])`)
+	tcpMovementRegexp = regexp.MustCompile(`(?im)^PRESENT MOVEMENT\.\.\.(.+)$`)
+	tcpDegreesRegexp  = regexp.MustCompile(`(?i)([0-9]+) DEGREES AT\s+([0-9]+) MPH`)
+	tcpPressureRegexp = regexp.MustCompile(`(?i)MINIMUM CENTRAL PRESSURE\.\.\.([0-9]+) MB`)
+	tcpWindRegexp     = regexp.MustCompile(`(?i)MAXIMUM SUSTAINED WINDS\.\.\.([0-9]+) MPH`)
+
+	tropicalPointRegexp = regexp.MustCompile(`^([0-9.]+)([NS])\s+([0-9.]+)([EW])$`)
+)
+
+func ParseTCM(text string) (*TCM, error) {
+	tcm := TCM{
+		Original: text,
+		Radii:    []WindRadii{},
+		Forecast: []TropicalForecast{},
+	}
+
+	var err error
+	tcm.StormID, tcm.Type, tcm.Name, tcm.Advisory, tcm.Issued, err = parseTropicalHeader(ComponentTCM, text)
+	if err != nil {
+		return nil, err
+	}
+
+	// The current position and intensity come before the forecasts
+	current := text
+	forecasts := tcmForecastRegexp.FindAllStringSubmatchIndex(text, -1)
+	if len(forecasts) > 0 {
+		current = text[:forecasts[0][0]]
+	}
+
+	match := tcmCenterRegexp.FindStringSubmatchIndex(current)
+	if match == nil {
+		return nil, awips.NewParseError(ComponentTCM, text, -1, errors.New("no center location found"))
+	}
+	tcm.Position, err = parseTropicalPoint(text[match[2]:match[3]])
+	if err != nil {
+		return nil, awips.NewParseError(ComponentTCM, text, match[0], err)
+	}
+	tcm.Time, err = time.Parse("02/1504Z", text[match[4]:match[5]])
+	if err != nil {
+		return nil, awips.NewParseError(ComponentTCM, text, match[0], fmt.Errorf("could not parse center time: %w", err))
+	}
+
+	if match := tcmMovementRegexp.FindStringSubmatch(current); match != nil {
+		tcm.Movement.Direction, _ = strconv.Atoi(match[1])
+		tcm.Movement.Speed, _ = strconv.Atoi(match[2])
+	} else if strings.Contains(current, "STATIONARY") {
+		tcm.Movement.Stationary = true
+	}
+
+	if match := tcmPressureRegexp.FindStringSubmatch(current); match != nil {
+		tcm.Pressure, _ = strconv.Atoi(match[1])
+	}
+
+	if match := tcmWindRegexp.FindStringSubmatch(current); match != nil {
+		tcm.MaxWind, _ = strconv.Atoi(match[1])
+		tcm.Gusts, _ = strconv.Atoi(match[2])
+	} else {
+		return nil, awips.NewParseError(ComponentTCM, text, -1, errors.New("no max sustained winds found"))
+	}
+
+	tcm.Radii = parseWindRadii(current)
+
+	track := [][]float64{tcm.Position}
+
+	for i, match := range forecasts {
+		end := len(text)
+		if i+1 < len(forecasts) {
+			end = forecasts[i+1][0]
+		}
+		section := text[match[0]:end]
+
+		forecast := TropicalForecast{
+			Radii: []WindRadii{},
+		}
+		forecast.Time, err = time.Parse("02/1504Z", text[match[2]:match[3]])
+		if err != nil {
+			return nil, awips.NewParseError(ComponentTCM, text, match[0], fmt.Errorf("could not parse forecast time: %w", err))
+		}
+		if match[4] >= 0 {
+			forecast.Position, err = parseTropicalPoint(text[match[4]:match[5]])
+			if err != nil {
+				return nil, awips.NewParseError(ComponentTCM, text, match[0], err)
+			}
+			track = append(track, forecast.Position)
+		}
+		if match[6] >= 0 {
+			forecast.Status = strings.TrimSpace(text[match[6]:match[7]])
+		}
+
+		if wind := tcmMaxWindRegexp.FindStringSubmatch(section); wind != nil {
+			forecast.MaxWind, _ = strconv.Atoi(wind[1])
+			forecast.Gusts, _ = strconv.Atoi(wind[2])
+		}
+		forecast.Radii = parseWindRadii(section)
+
+		tcm.Forecast = append(tcm.Forecast, forecast)
+	}
+
+	// A line needs at least two positions, so there is no track for a storm without forecast positions
+	if len(track) >= 2 {
+		tcm.Track = &awips.LineStringFeature{
+			Type:        "LineString",
+			Coordinates: track,
+		}
+	}
+
+	return &tcm, nil
+}
+
+func ParseTCP(text string) (*TCP, error) {
+	tcp := TCP{
+		Original: text,
+	}
+
+	var err error
+	tcp.StormID, tcp.Type, tcp.Name, tcp.Advisory, tcp.Issued, err = parseTropicalHeader(ComponentTCP, text)
+	if err != nil {
+		return nil, err
+	}
+
+	match := tcpLocationRegexp.FindStringSubmatchIndex(text)
+	if match == nil {
+		return nil, awips.NewParseError(ComponentTCP, text, -1, errors.New("no location found"))
+	}
+	tcp.Position, err = parseTropicalPoint(text[match[2]:match[3]])
+	if err != nil {
+		return nil, awips.NewParseError(ComponentTCP, text, match[0], err)
+	}
+
+	if match := tcpMovementRegexp.FindStringSubmatch(text); match != nil {
+		if degrees := tcpDegreesRegexp.FindStringSubmatch(match[1]); degrees != nil {
+			tcp.Movement.Direction, _ = strconv.Atoi(degrees[1])
+			tcp.Movement.Speed, _ = strconv.Atoi(degrees[2])
+		} else if strings.Contains(strings.ToUpper(match[1]), "STATIONARY") {
+			tcp.Movement.Stationary = true
+		}
+	}
+
+	if match := tcpPressureRegexp.FindStringSubmatch(text); match != nil {
+		tcp.Pressure, _ = strconv.Atoi(match[1])
+	}
+
+	if match := tcpWindRegexp.FindStringSubmatch(text); match != nil {
+		tcp.MaxWind, _ = strconv.Atoi(match[1])
+	} else {
+		return nil, awips.NewParseError(ComponentTCP, text, -1, errors.New("no maximum sustained winds found"))
+	}
+
+	return &tcp, nil
+}
+
+// Finds the storm ID, the type and name of the storm, the advisory number, and when the advisory was issued.
+// e.g. HURRICANE ERIN FORECAST/ADVISORY NUMBER 20
+func parseTropicalHeader(component string, text string) (string, string, string, string, time.Time, error) {
+	match := tropicalTitleRegexp.FindStringSubmatchIndex(text)
+	if match == nil {
+		return "", "", "", "", time.Time{}, awips.NewParseError(component, text, -1, errors.New("no advisory number found"))
+	}
+	title := strings.ToUpper(strings.TrimSpace(text[match[2]:match[3]]))
+	advisory := strings.ToUpper(text[match[4]:match[5]])
+
+	kind, name := "", title
+	for _, t := range tropicalTypes {
+		if strings.HasPrefix(title, t+" ") {
+			kind, name = t, strings.TrimSpace(strings.TrimPrefix(title, t))
+			break
+		}
+	}
+
+	id := tropicalStormIDRegexp.FindString(text)
+	if id == "" {
+		return "", "", "", "", time.Time{}, awips.NewParseError(component, text, match[0], errors.New("no storm ID found"))
+	}
+
+	issued, err := awips.GetIssuedTime(text)
+	if err != nil {
+		return "", "", "", "", time.Time{}, err
+	}
+
+	return id, kind, name, advisory, issued, nil
+}
+
+// Parses a position such as 33.9N  72.5W into [lon, lat]
+func parseTropicalPoint(s string) ([]float64, error) {
+	match := tropicalPointRegexp.FindStringSubmatch(strings.TrimSpace(s))
+	if match == nil {
+		return nil, fmt.Errorf("could not parse position %s", s)
+	}
+	lat, err := strconv.ParseFloat(match[1], 64)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse latitude %s: %w", match[1], err)
+	}
+	lon, err := strconv.ParseFloat(match[3], 64)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse longitude %s: %w", match[3], err)
+	}
+	if match[2] == "S" {
+		lat = -lat
+	}
+	if match[4] == "W" {
+		lon = -lon
+	}
+	return []float64{lon, lat}, nil
+}
+
+// Finds the wind radii lines, e.g. 34 KT.......230NE 200SE 150SW 170NW.
+func parseWindRadii(text string) []WindRadii {
+	radii := []WindRadii{}
+	for _, match := range tropicalRadiiRegexp.FindAllStringSubmatch(text, -1) {
+		r := WindRadii{}
+		r.Speed, _ = strconv.Atoi(match[1])
+		r.NE, _ = strconv.Atoi(match[2])
+		r.SE, _ = strconv.Atoi(match[3])
+		r.SW, _ = strconv.Atoi(match[4])
+		r.NW, _ = strconv.Atoi(match[5])
+		radii = append(radii, r)
+	}
+	return radii
+}
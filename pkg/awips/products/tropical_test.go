@@ -0,0 +1,250 @@
+package products
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/metdatasystem/mds-awips/pkg/awips"
+)
+
+const testTCM = `WTNT25 KNHC 211450
+TCMAT5
+
+HURRICANE ERIN FORECAST/ADVISORY NUMBER  20
+NWS NATIONAL HURRICANE CENTER MIAMI FL       AL052025
+1500 UTC THU AUG 21 2025
+
+THERE ARE NO COASTAL WATCHES OR WARNINGS IN EFFECT.
+
+HURRICANE CENTER LOCATED NEAR 33.9N  72.5W AT 21/1500Z
+POSITION ACCURATE WITHIN  20 NM
+
+PRESENT MOVEMENT TOWARD THE NORTHEAST OR  40 DEGREES AT  15 KT
+
+ESTIMATED MINIMUM CENTRAL PRESSURE  950 MB
+EYE DIAMETER  30 NM
+MAX SUSTAINED WINDS  90 KT WITH GUSTS TO 110 KT.
+64 KT....... 90NE  80SE  50SW  60NW.
+50 KT.......150NE 140SE  90SW 110NW.
+34 KT.......230NE 200SE 150SW 170NW.
+12 FT SEAS..540NE 480SE 420SW 480NW.
+WINDS AND SEAS VARY GREATLY IN EACH QUADRANT.  RADII IN NAUTICAL
+MILES ARE THE LARGEST RADII EXPECTED ANYWHERE IN THAT QUADRANT.
+
+REPEAT...CENTER LOCATED NEAR 33.9N  72.5W AT 21/1500Z
+AT 21/1200Z CENTER WAS LOCATED NEAR 33.2N  73.2W
+
+FORECAST VALID 22/0000Z 35.2N  70.0W
+MAX WIND  85 KT...GUSTS 105 KT.
+64 KT... 90NE  80SE  50SW  60NW.
+50 KT...150NE 140SE  90SW 110NW.
+34 KT...240NE 210SE 160SW 180NW.
+
+FORECAST VALID 22/1200Z 37.0N  65.5W
+MAX WIND  80 KT...GUSTS 100 KT.
+34 KT...250NE 220SE 170SW 190NW.
+
+EXTENDED OUTLOOK. NOTE...ERRORS FOR TRACK HAVE AVERAGED NEAR 125 NM
+ON DAY 4 AND 175 NM ON DAY 5...AND FOR INTENSITY NEAR 15 KT EACH DAY
+
+OUTLOOK VALID 25/1200Z 48.0N  40.0W...POST-TROP/EXTRATROP
+MAX WIND  55 KT...GUSTS  65 KT.
+
+OUTLOOK VALID 26/1200Z...DISSIPATED
+
+REQUEST FOR 3 HOURLY SHIP REPORTS WITHIN 300 MILES OF 33.9N 72.5W
+
+NEXT ADVISORY AT 21/2100Z
+
+$$
+FORECASTER BLAKE
+`
+
+const testTCP = `WTNT35 KNHC 211500
+TCPAT5
+
+BULLETIN
+Hurricane Erin Intermediate Advisory Number  20A
+NWS National Hurricane Center Miami FL       AL052025
+1100 AM EDT Thu Aug 21 2025
+
+...ERIN CONTINUES NORTHEASTWARD AWAY FROM THE UNITED STATES...
+
+SUMMARY OF 1100 AM EDT...1500 UTC...INFORMATION
+-----------------------------------------------
+LOCATION...33.9N 72.5W
+ABOUT 215 MI...345 KM ESE OF CAPE HATTERAS NORTH CAROLINA
+MAXIMUM SUSTAINED WINDS...105 MPH...165 KM/H
+PRESENT MOVEMENT...NE OR 40 DEGREES AT 17 MPH...28 KM/H
+MINIMUM CENTRAL PRESSURE...950 MB...28.05 INCHES
+
+$$
+Forecaster Blake
+`
+
+func TestTCMParse(t *testing.T) {
+	tcm, err := ParseTCM(testTCM)
+	if err != nil {
+		t.Fatalf("failed to parse TCM: %v", err)
+	}
+
+	if tcm.StormID != "AL052025" || tcm.Type != "HURRICANE" || tcm.Name != "ERIN" || tcm.Advisory != "20" {
+		t.Errorf("expected AL052025 HURRICANE ERIN 20, got %s %s %s %s", tcm.StormID, tcm.Type, tcm.Name, tcm.Advisory)
+	}
+	if tcm.Issued.UTC().Day() != 21 || tcm.Issued.UTC().Hour() != 15 {
+		t.Errorf("expected issued 211500Z, got '%s'", tcm.Issued)
+	}
+	if tcm.Time.Day() != 21 || tcm.Time.Hour() != 15 {
+		t.Errorf("expected time 211500Z, got '%s'", tcm.Time)
+	}
+	if tcm.Position[0] != -72.5 || tcm.Position[1] != 33.9 {
+		t.Errorf("expected position [-72.5 33.9], got %v", tcm.Position)
+	}
+	if tcm.Movement.Stationary || tcm.Movement.Direction != 40 || tcm.Movement.Speed != 15 {
+		t.Errorf("expected movement 40 degrees at 15 KT, got %+v", tcm.Movement)
+	}
+	if tcm.Pressure != 950 || tcm.MaxWind != 90 || tcm.Gusts != 110 {
+		t.Errorf("expected 950 MB 90 KT gusts 110 KT, got %d MB %d KT gusts %d KT", tcm.Pressure, tcm.MaxWind, tcm.Gusts)
+	}
+
+	radii := []WindRadii{
+		{Speed: 64, NE: 90, SE: 80, SW: 50, NW: 60},
+		{Speed: 50, NE: 150, SE: 140, SW: 90, NW: 110},
+		{Speed: 34, NE: 230, SE: 200, SW: 150, NW: 170},
+	}
+	if len(tcm.Radii) != len(radii) {
+		t.Fatalf("expected %d radii, got %d", len(radii), len(tcm.Radii))
+	}
+	for i, r := range radii {
+		if tcm.Radii[i] != r {
+			t.Errorf("expected radii %+v, got %+v", r, tcm.Radii[i])
+		}
+	}
+
+	if len(tcm.Forecast) != 4 {
+		t.Fatalf("expected 4 forecasts, got %d", len(tcm.Forecast))
+	}
+	first := tcm.Forecast[0]
+	if first.Time.Day() != 22 || first.Time.Hour() != 0 || first.MaxWind != 85 || first.Gusts != 105 || len(first.Radii) != 3 {
+		t.Errorf("unexpected first forecast %+v", first)
+	}
+	if tcm.Forecast[2].Status != "POST-TROP/EXTRATROP" || tcm.Forecast[2].MaxWind != 55 {
+		t.Errorf("expected post-tropical 55 KT, got %s %d KT", tcm.Forecast[2].Status, tcm.Forecast[2].MaxWind)
+	}
+	if tcm.Forecast[3].Status != "DISSIPATED" || tcm.Forecast[3].Position != nil {
+		t.Errorf("expected dissipated with no position, got %s %v", tcm.Forecast[3].Status, tcm.Forecast[3].Position)
+	}
+
+	track := tcm.Track.Coordinates
+	if tcm.Track.Type != "LineString" || len(track) != 4 {
+		t.Fatalf("expected a track of 4 points, got %d", len(track))
+	}
+	if track[3][0] != -40 || track[3][1] != 48 {
+		t.Errorf("expected last track point [-40 48], got %v", track[3])
+	}
+}
+
+func TestTCPParse(t *testing.T) {
+	tcp, err := ParseTCP(testTCP)
+	if err != nil {
+		t.Fatalf("failed to parse TCP: %v", err)
+	}
+
+	if tcp.StormID != "AL052025" || tcp.Type != "HURRICANE" || tcp.Name != "ERIN" || tcp.Advisory != "20A" {
+		t.Errorf("expected AL052025 HURRICANE ERIN 20A, got %s %s %s %s", tcp.StormID, tcp.Type, tcp.Name, tcp.Advisory)
+	}
+	if tcp.Position[0] != -72.5 || tcp.Position[1] != 33.9 {
+		t.Errorf("expected position [-72.5 33.9], got %v", tcp.Position)
+	}
+	if tcp.Movement.Direction != 40 || tcp.Movement.Speed != 17 {
+		t.Errorf("expected movement 40 degrees at 17 MPH, got %+v", tcp.Movement)
+	}
+	if tcp.Pressure != 950 || tcp.MaxWind != 105 {
+		t.Errorf("expected 950 MB 105 MPH, got %d MB %d MPH", tcp.Pressure, tcp.MaxWind)
+	}
+}
+
+func TestTCPParseStationary(t *testing.T) {
+	text := `WTNT33 KNHC 021500
+TCPAT3
+
+BULLETIN
+Tropical Depression Three Advisory Number   4
+NWS National Hurricane Center Miami FL       AL032025
+1100 AM EDT Wed Jul 02 2025
+
+LOCATION...29.1N 79.8W
+MAXIMUM SUSTAINED WINDS...35 MPH...55 KM/H
+PRESENT MOVEMENT...STATIONARY
+MINIMUM CENTRAL PRESSURE...1010 MB...29.83 INCHES
+`
+	tcp, err := ParseTCP(text)
+	if err != nil {
+		t.Fatalf("failed to parse TCP: %v", err)
+	}
+	if tcp.Type != "TROPICAL DEPRESSION" || tcp.Name != "THREE" {
+		t.Errorf("expected TROPICAL DEPRESSION THREE, got %s %s", tcp.Type, tcp.Name)
+	}
+	if !tcp.Movement.Stationary {
+		t.Errorf("expected stationary movement, got %+v", tcp.Movement)
+	}
+}
+
+func TestTCMParseDissipated(t *testing.T) {
+	// Only the current position and a forecast without one
+	text := testTCM[:strings.Index(testTCM, "FORECAST VALID")] + "OUTLOOK VALID 22/0000Z...DISSIPATED\n\n$$\n"
+
+	tcm, err := ParseTCM(text)
+	if err != nil {
+		t.Fatalf("failed to parse TCM: %v", err)
+	}
+	if len(tcm.Forecast) != 1 || tcm.Forecast[0].Status != "DISSIPATED" {
+		t.Fatalf("expected a dissipated forecast, got %+v", tcm.Forecast)
+	}
+
+	// A single position is not a line
+	if tcm.Track != nil {
+		t.Errorf("expected no track, got %+v", tcm.Track)
+	}
+	data, err := json.Marshal(tcm)
+	if err != nil {
+		t.Fatalf("failed to marshal TCM: %v", err)
+	}
+	if strings.Contains(string(data), `"track"`) {
+		t.Errorf("expected the track to be left out of %s", data)
+	}
+}
+
+func TestTCMParseInvalidPosition(t *testing.T) {
+	text := `WTNT25 KNHC 211450
+TCMAT5
+
+HURRICANE ERIN FORECAST/ADVISORY NUMBER  20
+NWS NATIONAL HURRICANE CENTER MIAMI FL       AL052025
+1500 UTC THU AUG 21 2025
+
+HURRICANE CENTER LOCATED NEAR 33.9.9N  72.5W AT 21/1500Z
+`
+	_, err := ParseTCM(text)
+
+	var parseErr *awips.ParseError
+	if !errors.As(err, &parseErr) || parseErr.Component != ComponentTCM {
+		t.Fatalf("expected TCM ParseError, got %v", err)
+	}
+	if parseErr.Line != 8 {
+		t.Errorf("expected error on line 8, got %d", parseErr.Line)
+	}
+}
+
+func FuzzParseTropical(f *testing.F) {
+	f.Add(testTCM)
+	f.Add(testTCP)
+	f.Fuzz(func(t *testing.T, text string) {
+		_, err := ParseTCM(text)
+		checkParseError(t, err)
+		_, err = ParseTCP(text)
+		checkParseError(t, err)
+	})
+}
@@ -0,0 +1,271 @@
+package products
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/metdatasystem/mds-awips/pkg/awips"
+)
+
+const (
+	ComponentSEL = "sel"
+	ComponentSAW = "saw"
+	ComponentWOU = "wou"
+)
+
+// A Severe Weather Watch (SEL) issued by the SPC
+type SEL struct {
+	Original  string       `json:"original"`
+	Phenomena string       `json:"phenomena"` // TO or SV as in the watch VTEC
+	Number    int          `json:"number"`
+	PDS       bool         `json:"pds"`
+	Issued    time.Time    `json:"issued"`  // Only day, hour, minute
+	Expires   time.Time    `json:"expires"` // Only day, hour, minute
+	Summary   WatchSummary `json:"summary"`
+}
+
+// An Aviation Watch (SAW) issued by the SPC, the approximate area of a watch
+type SAW struct {
+	Original  string               `json:"original"`
+	Phenomena string               `json:"phenomena"` // TO or SV as in the watch VTEC
+	Number    int                  `json:"number"`
+	PDS       bool                 `json:"pds"`
+	States    []string             `json:"states"`
+	Issued    time.Time            `json:"issued"`  // Only day, hour, minute
+	Expires   time.Time            `json:"expires"` // Only day, hour, minute
+	Axis      string               `json:"axis"`    // The description of the parallelogram, e.g. 70 STATUTE MILES EAST AND WEST OF LINE
+	Polygon   awips.PolygonFeature `json:"polygon"`
+	Summary   WatchSummary         `json:"summary"`
+}
+
+// A Watch Outline Update (WOU) issued by the SPC, the counties in a watch
+type WOU struct {
+	Original  string    `json:"original"`
+	Phenomena string    `json:"phenomena"` // TO or SV as in the watch VTEC
+	Number    int       `json:"number"`
+	PDS       bool      `json:"pds"`
+	Expires   time.Time `json:"expires"`  // Only day, hour, minute
+	Counties  []string  `json:"counties"` // Counties in the watch, e.g. NCC063
+	Removed   []string  `json:"removed"`  // Counties cancelled or expired from the watch
+}
+
+// The aviation summary of a watch. Values are zero when they are not given.
+type WatchSummary struct {
+	Hail            float64 `json:"hail"`             // Inches
+	Gust            int     `json:"gust"`             // Knots
+	Tops            int     `json:"tops"`             // Hundreds of feet
+	MotionDirection int     `json:"motion_direction"` // Degrees
+	MotionSpeed     int     `json:"motion_speed"`     // Knots
+}
+
+var (
+	selNumberRegexp = regexp.MustCompile(`(?i)(Tornado|Severe Thunderstorm) Watch Number ([0-9]+)`)
+	sawHeaderRegexp = regexp.MustCompile(`(?m)^WW ([0-9]+) (TORNADO|SEVERE TSTM) ((?:[A-Z]{2} )*)([0-9]{6}Z) - ([0-9]{6}Z)`)
+	sawAxisRegexp   = regexp.MustCompile(`(?m)^AXIS\.\.(.+?)\.\.$`)
+	wouNumberRegexp = regexp.MustCompile(`(?i)WATCH OUTLINE UPDATE FOR W([ST]) ([0-9]+)`)
+	pdsRegexp       = regexp.MustCompile(`(?i)PARTICULARLY DANGEROUS SITUATION`)
+
+	summaryHailRegexp   = regexp.MustCompile(`(?i)HAIL SURFACE AND ALOFT(?:\.\.| TO )([0-9.]+) INCH`)
+	summaryGustRegexp   = regexp.MustCompile(`(?i)WIND GUSTS(?:\.\.| TO )([0-9]+) KNOTS`)
+	summaryTopsRegexp   = regexp.MustCompile(`(?i)MAX(?:IMUM)? TOPS TO ([0-9]+)`)
+	summaryMotionRegexp = regexp.MustCompile(`(?i)MEAN STORM MOTION VECTOR ([0-9]{3})([0-9]{2,3})`)
+)
+
+func ParseSEL(text string) (*SEL, error) {
+	match := selNumberRegexp.FindStringSubmatchIndex(text)
+	if match == nil {
+		return nil, awips.NewParseError(ComponentSEL, text, -1, errors.New("no watch number found"))
+	}
+	number, err := strconv.Atoi(text[match[4]:match[5]])
+	if err != nil {
+		return nil, awips.NewParseError(ComponentSEL, text, match[0], err)
+	}
+
+	wmo, err := awips.ParseWMO(text)
+	if err != nil {
+		return nil, err
+	}
+
+	// The watch times are only given in local time, so the expiry comes from the UGC
+	ugc, err := awips.ParseUGC(text)
+	if err != nil {
+		return nil, err
+	}
+	if ugc == nil {
+		return nil, awips.NewParseError(ComponentSEL, text, -1, errors.New("no UGC found"))
+	}
+
+	summary, err := parseWatchSummary(text)
+	if err != nil {
+		return nil, awips.NewParseError(ComponentSEL, text, -1, err)
+	}
+
+	return &SEL{
+		Original:  text,
+		Phenomena: watchPhenomena(text[match[2]:match[3]]),
+		Number:    number,
+		PDS:       pdsRegexp.MatchString(text),
+		Issued:    wmo.Issued,
+		Expires:   ugc.Expires,
+		Summary:   summary,
+	}, nil
+}
+
+func ParseSAW(text string) (*SAW, error) {
+	match := sawHeaderRegexp.FindStringSubmatchIndex(text)
+	if match == nil {
+		return nil, awips.NewParseError(ComponentSAW, text, -1, errors.New("no watch header found"))
+	}
+	group := func(i int) string {
+		return text[match[2*i]:match[2*i+1]]
+	}
+
+	number, err := strconv.Atoi(group(1))
+	if err != nil {
+		return nil, awips.NewParseError(ComponentSAW, text, match[0], err)
+	}
+	issued, err := time.Parse("021504Z", group(4))
+	if err != nil {
+		return nil, awips.NewParseError(ComponentSAW, text, match[0], fmt.Errorf("could not parse issued time: %w", err))
+	}
+	expires, err := time.Parse("021504Z", group(5))
+	if err != nil {
+		return nil, awips.NewParseError(ComponentSAW, text, match[0], fmt.Errorf("could not parse expire time: %w", err))
+	}
+
+	axis := ""
+	if axisMatch := sawAxisRegexp.FindStringSubmatch(text); axisMatch != nil {
+		axis = strings.TrimSpace(axisMatch[1])
+	}
+
+	latlon, err := awips.ParseLatLon(text)
+	if err != nil {
+		return nil, err
+	}
+	if latlon == nil {
+		return nil, awips.NewParseError(ComponentSAW, text, -1, errors.New("no LAT...LON found"))
+	}
+
+	summary, err := parseWatchSummary(text)
+	if err != nil {
+		return nil, awips.NewParseError(ComponentSAW, text, -1, err)
+	}
+
+	return &SAW{
+		Original:  text,
+		Phenomena: watchPhenomena(group(2)),
+		Number:    number,
+		PDS:       pdsRegexp.MatchString(text),
+		States:    strings.Fields(group(3)),
+		Issued:    issued,
+		Expires:   expires,
+		Axis:      axis,
+		Polygon:   *latlon.Polygon,
+		Summary:   summary,
+	}, nil
+}
+
+// Parses a WOU. Each segment lists counties by UGC with the VTEC of the watch, counties in segments that are cancelled
+// or expired are no longer in the watch.
+func ParseWOU(text string) (*WOU, error) {
+	match := wouNumberRegexp.FindStringSubmatchIndex(text)
+	if match == nil {
+		return nil, awips.NewParseError(ComponentWOU, text, -1, errors.New("no watch number found"))
+	}
+	number, err := strconv.Atoi(text[match[4]:match[5]])
+	if err != nil {
+		return nil, awips.NewParseError(ComponentWOU, text, match[0], err)
+	}
+
+	phenomena := "SV"
+	if text[match[2]:match[3]] == "T" {
+		phenomena = "TO"
+	}
+
+	wou := WOU{
+		Original:  text,
+		Phenomena: phenomena,
+		Number:    number,
+		PDS:       pdsRegexp.MatchString(text),
+		Counties:  []string{},
+		Removed:   []string{},
+	}
+
+	for _, segment := range strings.Split(text, "$$") {
+		ugc, err := awips.ParseUGC(segment)
+		if err != nil {
+			return nil, err
+		}
+		if ugc == nil {
+			continue
+		}
+
+		vtecs, errs := awips.ParseVTEC(segment)
+		if len(errs) > 0 {
+			return nil, errs[0]
+		}
+
+		if wou.Expires.IsZero() {
+			wou.Expires = ugc.Expires
+		}
+
+		removed := false
+		for _, vtec := range vtecs {
+			if vtec.Action == "CAN" || vtec.Action == "EXP" || vtec.Action == "UPG" {
+				removed = true
+			}
+		}
+
+		if removed {
+			wou.Removed = append(wou.Removed, ugc.Codes()...)
+		} else {
+			wou.Counties = append(wou.Counties, ugc.Codes()...)
+		}
+	}
+
+	if len(wou.Counties) == 0 && len(wou.Removed) == 0 {
+		return nil, awips.NewParseError(ComponentWOU, text, -1, errors.New("no counties found"))
+	}
+
+	return &wou, nil
+}
+
+// Finds the aviation summary of a watch, written as e.g. HAIL SURFACE AND ALOFT..1.5 INCHES in the SAW
+// and hail surface and aloft to 1.5 inches in the SEL
+func parseWatchSummary(text string) (WatchSummary, error) {
+	// The summary is wrapped over lines
+	text = strings.Join(strings.Fields(text), " ")
+
+	summary := WatchSummary{}
+	var err error
+
+	if match := summaryHailRegexp.FindStringSubmatch(text); match != nil {
+		summary.Hail, err = strconv.ParseFloat(strings.TrimSuffix(match[1], "."), 64)
+		if err != nil {
+			return summary, fmt.Errorf("could not parse hail size: %w", err)
+		}
+	}
+	if match := summaryGustRegexp.FindStringSubmatch(text); match != nil {
+		summary.Gust, _ = strconv.Atoi(match[1])
+	}
+	if match := summaryTopsRegexp.FindStringSubmatch(text); match != nil {
+		summary.Tops, _ = strconv.Atoi(match[1])
+	}
+	if match := summaryMotionRegexp.FindStringSubmatch(text); match != nil {
+		summary.MotionDirection, _ = strconv.Atoi(match[1])
+		summary.MotionSpeed, _ = strconv.Atoi(match[2])
+	}
+
+	return summary, nil
+}
+
+func watchPhenomena(name string) string {
+	if strings.EqualFold(name, "Tornado") {
+		return "TO"
+	}
+	return "SV"
+}
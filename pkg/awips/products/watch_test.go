@@ -0,0 +1,230 @@
+package products
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/metdatasystem/mds-awips/pkg/awips"
+)
+
+const testSEL = `WWUS20 KWNS 262010
+SEL5
+SPC WW 262010
+NCZ000-VAZ000-270300-
+
+URGENT - IMMEDIATE BROADCAST REQUESTED
+Tornado Watch Number 455
+NWS Storm Prediction Center Norman OK
+410 PM EDT Thu Jun 26 2025
+
+The NWS Storm Prediction Center has issued a
+
+* Tornado Watch for portions of
+  Central and Eastern North Carolina
+  Southern Virginia
+
+* Effective this Thursday afternoon and evening from 410 PM until
+  1100 PM EDT.
+
+...THIS IS A PARTICULARLY DANGEROUS SITUATION...
+
+* Primary threats include...
+  Several tornadoes and a few intense tornadoes likely
+
+&&
+
+AVIATION...Tornadoes and a few severe thunderstorms with hail surface
+and aloft to 1.5 inches. Extreme turbulence and surface wind gusts to
+65 knots. A few cumulonimbi with maximum tops to 500. Mean storm
+motion vector 25030.
+
+...Smith
+`
+
+const testSAW = `WWUS30 KWNS 262010
+SAW5
+SPC AWW 262010
+WW 455 TORNADO NC VA 262010Z - 270300Z
+AXIS..70 STATUTE MILES EAST AND WEST OF LINE..
+35S FAY/FAYETTEVILLE NC/ - 40N AVC/SOUTH HILL VA/
+..AVIATION COORDS.. 60NM E/W /35SSE ILM - 20SE LYH/
+HAIL SURFACE AND ALOFT..1.5 INCHES. WIND GUSTS..65 KNOTS.
+MAX TOPS TO 500. MEAN STORM MOTION VECTOR 25030.
+
+LAT...LON 34437980 37097903 37097645 34437737
+
+THIS IS AN APPROXIMATION TO THE WATCH AREA.  FOR A
+COMPLETE DEPICTION OF THE WATCH SEE WOUS64 KWNS
+FOR WOU5.
+`
+
+const testWOU = `WOUS64 KWNS 262215
+WOU5
+
+BULLETIN - IMMEDIATE BROADCAST REQUESTED
+TORNADO WATCH OUTLINE UPDATE FOR WT 455
+NWS STORM PREDICTION CENTER NORMAN OK
+615 PM EDT THU JUN 26 2025
+
+TORNADO WATCH 455 IS IN EFFECT UNTIL 1100 PM EDT
+FOR THE FOLLOWING LOCATIONS
+
+NCC001-033-037-270300-
+/O.CON.KWNS.TO.A.0455.000000T0000Z-250627T0300Z/
+
+NC
+.    NORTH CAROLINA COUNTIES INCLUDED ARE
+
+ALAMANCE             CASWELL             CHATHAM
+
+$$
+
+VAC025-081-262215-
+/O.CAN.KWNS.TO.A.0455.000000T0000Z-250627T0300Z/
+
+VA
+.    VIRGINIA COUNTIES EXCLUDED ARE
+
+BRUNSWICK            GREENSVILLE
+
+$$
+
+ATTN...WFO...RAH...AKQ...
+`
+
+func TestSELParse(t *testing.T) {
+	sel, err := ParseSEL(testSEL)
+	if err != nil {
+		t.Fatalf("failed to parse SEL: %v", err)
+	}
+
+	if sel.Phenomena != "TO" || sel.Number != 455 {
+		t.Errorf("expected TO 455, got %s %d", sel.Phenomena, sel.Number)
+	}
+	if !sel.PDS {
+		t.Errorf("expected PDS")
+	}
+	if sel.Issued.Day() != 26 || sel.Issued.Hour() != 20 || sel.Issued.Minute() != 10 {
+		t.Errorf("expected issued 262010Z, got '%s'", sel.Issued)
+	}
+	if sel.Expires.Day() != 27 || sel.Expires.Hour() != 3 {
+		t.Errorf("expected expires 270300Z, got '%s'", sel.Expires)
+	}
+
+	expected := WatchSummary{Hail: 1.5, Gust: 65, Tops: 500, MotionDirection: 250, MotionSpeed: 30}
+	if sel.Summary != expected {
+		t.Errorf("expected summary %+v, got %+v", expected, sel.Summary)
+	}
+}
+
+func TestSAWParse(t *testing.T) {
+	saw, err := ParseSAW(testSAW)
+	if err != nil {
+		t.Fatalf("failed to parse SAW: %v", err)
+	}
+
+	if saw.Phenomena != "TO" || saw.Number != 455 {
+		t.Errorf("expected TO 455, got %s %d", saw.Phenomena, saw.Number)
+	}
+	if saw.PDS {
+		t.Errorf("expected no PDS")
+	}
+	if len(saw.States) != 2 || saw.States[0] != "NC" || saw.States[1] != "VA" {
+		t.Errorf("expected states NC VA, got %v", saw.States)
+	}
+	if saw.Issued.Day() != 26 || saw.Issued.Hour() != 20 || saw.Issued.Minute() != 10 {
+		t.Errorf("expected issued 262010Z, got '%s'", saw.Issued)
+	}
+	if saw.Expires.Day() != 27 || saw.Expires.Hour() != 3 {
+		t.Errorf("expected expires 270300Z, got '%s'", saw.Expires)
+	}
+	if saw.Axis != "70 STATUTE MILES EAST AND WEST OF LINE" {
+		t.Errorf("expected axis '70 STATUTE MILES EAST AND WEST OF LINE', got '%s'", saw.Axis)
+	}
+
+	ring := saw.Polygon.Coordinates[0]
+	if len(ring) != 5 {
+		t.Fatalf("expected 5 polygon points, got %d", len(ring))
+	}
+	if ring[0][0] != -79.80 || ring[0][1] != 34.43 {
+		t.Errorf("expected first point [-79.80 34.43], got %v", ring[0])
+	}
+
+	expected := WatchSummary{Hail: 1.5, Gust: 65, Tops: 500, MotionDirection: 250, MotionSpeed: 30}
+	if saw.Summary != expected {
+		t.Errorf("expected summary %+v, got %+v", expected, saw.Summary)
+	}
+}
+
+func TestWOUParse(t *testing.T) {
+	wou, err := ParseWOU(testWOU)
+	if err != nil {
+		t.Fatalf("failed to parse WOU: %v", err)
+	}
+
+	if wou.Phenomena != "TO" || wou.Number != 455 {
+		t.Errorf("expected TO 455, got %s %d", wou.Phenomena, wou.Number)
+	}
+	if wou.Expires.Day() != 27 || wou.Expires.Hour() != 3 {
+		t.Errorf("expected expires 270300Z, got '%s'", wou.Expires)
+	}
+
+	counties := []string{"NCC001", "NCC033", "NCC037"}
+	if len(wou.Counties) != len(counties) {
+		t.Fatalf("expected counties %v, got %v", counties, wou.Counties)
+	}
+	for i, county := range counties {
+		if wou.Counties[i] != county {
+			t.Errorf("expected county %s, got %s", county, wou.Counties[i])
+		}
+	}
+
+	if len(wou.Removed) != 2 || wou.Removed[0] != "VAC025" || wou.Removed[1] != "VAC081" {
+		t.Errorf("expected removed [VAC025 VAC081], got %v", wou.Removed)
+	}
+}
+
+func TestWatchParseMissing(t *testing.T) {
+	var parseErr *awips.ParseError
+
+	_, err := ParseSEL("WWUS20 KWNS 262010\nSEL5\n")
+	if !errors.As(err, &parseErr) || parseErr.Component != ComponentSEL {
+		t.Errorf("expected SEL ParseError, got %v", err)
+	}
+
+	_, err = ParseSAW("WWUS30 KWNS 262010\nSAW5\nWW 455 TORNADO NC VA 262010Z - 270300Z\n")
+	if !errors.As(err, &parseErr) || parseErr.Component != ComponentSAW {
+		t.Errorf("expected SAW ParseError, got %v", err)
+	}
+
+	_, err = ParseWOU("WOUS64 KWNS 262215\nWOU5\nTORNADO WATCH OUTLINE UPDATE FOR WT 455\n")
+	if !errors.As(err, &parseErr) || parseErr.Component != ComponentWOU {
+		t.Errorf("expected WOU ParseError, got %v", err)
+	}
+}
+
+// Checks that a returned error is a ParseError
+func checkParseError(t *testing.T, err error) {
+	t.Helper()
+	if err == nil {
+		return
+	}
+	var parseErr *awips.ParseError
+	if !errors.As(err, &parseErr) {
+		t.Errorf("expected a ParseError, got %T: %v", err, err)
+	}
+}
+
+func FuzzParseWatch(f *testing.F) {
+	f.Add(testSEL)
+	f.Add(testSAW)
+	f.Add(testWOU)
+	f.Fuzz(func(t *testing.T, text string) {
+		_, err := ParseSEL(text)
+		checkParseError(t, err)
+		_, err = ParseSAW(text)
+		checkParseError(t, err)
+		_, err = ParseWOU(text)
+		checkParseError(t, err)
+	})
+}
@@ -1,108 +1,154 @@
 package awips
 
 import (
-	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
 )
 
-type tagOpt struct {
-	Tag       string
-	Regexp    string
-	Possibles []string
+/*
+Impact based warning (IBW) tags as described in NWS Directive 10-511 and 10-922.
+*/
+
+// How a threat was detected or how likely it is
+type TagDetection string
+
+const (
+	DetectionPossible       TagDetection = "POSSIBLE"
+	DetectionRadarIndicated TagDetection = "RADAR INDICATED"
+	DetectionObserved       TagDetection = "OBSERVED"
+)
+
+// The damage threat tier of a warning
+type DamageThreat string
+
+const (
+	DamageConsiderable DamageThreat = "CONSIDERABLE"
+	DamageDestructive  DamageThreat = "DESTRUCTIVE"
+	DamageCatastrophic DamageThreat = "CATASTROPHIC"
+)
+
+// The state of a dam or levee failure
+type FailureTag string
+
+const (
+	FailureImminent  FailureTag = "IMMINENT"
+	FailureOccurring FailureTag = "OCCURRING"
+)
+
+type ImpactTags struct {
+	Tornado          TagDetection `json:"tornado,omitempty"`
+	Damage           DamageThreat `json:"damage,omitempty"`
+	HailThreat       TagDetection `json:"hail_threat,omitempty"`
+	Hail             *HailTag     `json:"hail,omitempty"`
+	WindThreat       TagDetection `json:"wind_threat,omitempty"`
+	Wind             *WindTag     `json:"wind,omitempty"`
+	FlashFlood       TagDetection `json:"flash_flood,omitempty"`
+	ExpectedRainfall string       `json:"expected_rainfall,omitempty"`
+	DamFailure       FailureTag   `json:"dam_failure,omitempty"`
+	Spout            TagDetection `json:"spout,omitempty"`
+	SnowSquall       TagDetection `json:"snow_squall,omitempty"`
+	SnowSquallImpact string       `json:"snow_squall_impact,omitempty"`
 }
 
-var tags = []tagOpt{
-	{
-		Tag:       "tornado",
-		Regexp:    `TORNADO\.\.\.([A-Z ]+)`,
-		Possibles: []string{"POSSIBLE", "RADAR INDICATED", "OBSERVED"},
-	},
-	{
-		Tag:       "damage",
-		Regexp:    `(TORNADO|THUNDERSTORM|FLASH FLOOD) DAMAGE THREAT\.\.\.([A-Z ]+)`,
-		Possibles: []string{"CONSIDERABLE", "DESTRUCTIVE", "CATASTROPHIC"},
-	},
-	{
-		Tag:       "hailThreat",
-		Regexp:    `HAIL THREAT\.\.\.([A-Z ]+)`,
-		Possibles: []string{"RADAR INDICATED", "OBSERVED"},
-	},
-	{
-		Tag:    "hail",
-		Regexp: `.*(HAIL|MAX HAIL SIZE)\.\.\.[><\.0-9]+\s?IN`,
-	},
-	{
-		Tag:       "windThreat",
-		Regexp:    `WIND THREAT\.\.\.([A-Z ]+)`,
-		Possibles: []string{"RADAR INDICATED", "OBSERVED"},
-	},
-	{
-		Tag:    "wind",
-		Regexp: `.*(WIND|MAX WIND GUST)\.\.\.[><\.0-9]+\s?(MPH|KTS)`,
-	},
-	{
-		Tag:       "flashFlood",
-		Regexp:    `FLASH FLOOD\.\.\.([A-Z ]+)`,
-		Possibles: []string{"RADAR INDICATED", "OBSERVED"},
-	},
-	{
-		Tag:    "expectedRainfall",
-		Regexp: `EXPECTED RAINFALL RATE\.\.\.(.)+`,
-	},
-	{
-		Tag:       "damFailure",
-		Regexp:    `(DAM|LEVEE) FAILURE\.\.\.(.)+`,
-		Possibles: []string{"IMMINENT", "OCCURRING"},
-	},
-	{
-		Tag:       "spout",
-		Regexp:    `.*(LANDSPOUT|WATERSPOUT)\.\.\.(.)+`,
-		Possibles: []string{"POSSIBLE", "OBSERVED"},
-	},
-	{
-		Tag:       "snowSquall",
-		Regexp:    `SNOW SQUALL\.\.\.([A-Z ]+)`,
-		Possibles: []string{"RADAR INDICATED", "OBSERVED"},
-	},
-	{
-		Tag:       "snowSquallImpact",
-		Regexp:    `SNOW SQUALL IMPACT\.\.\.([A-Z ]+)`,
-		Possibles: []string{"SIGNIFICANT"},
-	},
+// Maximum hail size in inches
+type HailTag struct {
+	Original   string  `json:"original"`
+	Size       float64 `json:"size"`
+	Comparison string  `json:"comparison,omitempty"` // < or > if the size is a bound
 }
 
-func ParseTags(text string) (map[string]string, []error) {
-	err := []error{}
+// Maximum wind gust
+type WindTag struct {
+	Original   string `json:"original"`
+	Speed      int    `json:"speed"`
+	Unit       string `json:"unit"`                 // MPH or KTS
+	Comparison string `json:"comparison,omitempty"` // < or > if the speed is a bound
+}
 
-	output := make(map[string]string)
-	for _, tag := range tags {
-		regex := regexp.MustCompile(tag.Regexp)
-		found := regex.FindString(text)
+var (
+	tornadoTagRegexp          = regexp.MustCompile(`(?im:^\s*TORNADO\.\.\.([A-Z ]+))`)
+	damageTagRegexp           = regexp.MustCompile(`(?im:^\s*(?:TORNADO|THUNDERSTORM|FLASH FLOOD) DAMAGE THREAT\.\.\.([A-Z ]+))`)
+	hailThreatTagRegexp       = regexp.MustCompile(`(?im:^\s*HAIL THREAT\.\.\.([A-Z ]+))`)
+	hailTagRegexp             = regexp.MustCompile(`(?im:^\s*(?:MAX HAIL SIZE|HAIL)\.\.\.([<>]?)\s*([0-9]*\.?[0-9]+)\s*IN)`)
+	windThreatTagRegexp       = regexp.MustCompile(`(?im:^\s*WIND THREAT\.\.\.([A-Z ]+))`)
+	windTagRegexp             = regexp.MustCompile(`(?im:^\s*(?:MAX WIND GUST|WIND)\.\.\.([<>]?)\s*([0-9]+)\s*(MPH|KTS))`)
+	flashFloodTagRegexp       = regexp.MustCompile(`(?im:^\s*FLASH FLOOD\.\.\.([A-Z ]+))`)
+	expectedRainfallTagRegexp = regexp.MustCompile(`(?im:^\s*EXPECTED RAINFALL RATE\.\.\.(.+))`)
+	damFailureTagRegexp       = regexp.MustCompile(`(?im:^\s*(?:DAM|LEVEE) FAILURE\.\.\.([A-Z ]+))`)
+	spoutTagRegexp            = regexp.MustCompile(`(?im:^\s*(?:LANDSPOUT|WATERSPOUT)\.\.\.([A-Z ]+))`)
+	snowSquallTagRegexp       = regexp.MustCompile(`(?im:^\s*SNOW SQUALL\.\.\.([A-Z ]+))`)
+	snowSquallImpactTagRegexp = regexp.MustCompile(`(?im:^\s*SNOW SQUALL IMPACT\.\.\.([A-Z ]+))`)
+)
 
-		if found == "" {
-			continue
-		}
+// Parses the IBW tags found in the text. Unusual tag values are still kept but are returned as errors.
+func ParseTags(text string) (ImpactTags, []error) {
+	err := []error{}
+	tags := ImpactTags{}
 
-		value := strings.Split(found, "...")[1]
+	tags.Tornado = findTag(tornadoTagRegexp, text, "tornado", &err, DetectionPossible, DetectionRadarIndicated, DetectionObserved)
+	tags.Damage = findTag(damageTagRegexp, text, "damage", &err, DamageConsiderable, DamageDestructive, DamageCatastrophic)
+	tags.HailThreat = findTag(hailThreatTagRegexp, text, "hailThreat", &err, DetectionRadarIndicated, DetectionObserved)
+	tags.WindThreat = findTag(windThreatTagRegexp, text, "windThreat", &err, DetectionRadarIndicated, DetectionObserved)
+	tags.FlashFlood = findTag(flashFloodTagRegexp, text, "flashFlood", &err, DetectionRadarIndicated, DetectionObserved)
+	tags.ExpectedRainfall = findTag[string](expectedRainfallTagRegexp, text, "expectedRainfall", &err)
+	tags.DamFailure = findTag(damFailureTagRegexp, text, "damFailure", &err, FailureImminent, FailureOccurring)
+	tags.Spout = findTag(spoutTagRegexp, text, "spout", &err, DetectionPossible, DetectionObserved)
+	tags.SnowSquall = findTag(snowSquallTagRegexp, text, "snowSquall", &err, DetectionRadarIndicated, DetectionObserved)
+	tags.SnowSquallImpact = findTag(snowSquallImpactTagRegexp, text, "snowSquallImpact", &err, "SIGNIFICANT")
 
-		if tag.Possibles != nil {
-			valid := false
-			for _, p := range tag.Possibles {
-				if p == value {
-					valid = true
-					break
-				}
+	if match := hailTagRegexp.FindStringSubmatchIndex(text); match != nil {
+		size, e := strconv.ParseFloat(text[match[4]:match[5]], 64)
+		if e != nil {
+			err = append(err, parseError(ComponentTags, text, match[0], "could not parse hail size"))
+		} else {
+			tags.Hail = &HailTag{
+				Original:   strings.TrimSpace(text[match[0]:match[1]]),
+				Size:       size,
+				Comparison: text[match[2]:match[3]],
 			}
+		}
+	}
 
-			if !valid {
-				err = append(err, fmt.Errorf("unusual tag found for %s: %s", tag.Tag, value))
+	if match := windTagRegexp.FindStringSubmatchIndex(text); match != nil {
+		speed, e := strconv.Atoi(text[match[4]:match[5]])
+		if e != nil {
+			err = append(err, parseError(ComponentTags, text, match[0], "could not parse wind speed"))
+		} else {
+			tags.Wind = &WindTag{
+				Original:   strings.TrimSpace(text[match[0]:match[1]]),
+				Speed:      speed,
+				Unit:       strings.ToUpper(text[match[6]:match[7]]),
+				Comparison: text[match[2]:match[3]],
 			}
 		}
+	}
 
-		output[tag.Tag] = value
+	return tags, err
+}
 
+// Finds the value of a tag, normalised to upper case. If possibles are given and the value is not one of them, an error is recorded.
+func findTag[T ~string](regex *regexp.Regexp, text string, name string, err *[]error, possibles ...T) T {
+	match := regex.FindStringSubmatchIndex(text)
+	if match == nil {
+		return ""
+	}
+
+	value := T(strings.ToUpper(strings.TrimSpace(text[match[2]:match[3]])))
+
+	if len(possibles) > 0 {
+		valid := false
+		for _, p := range possibles {
+			if p == value {
+				valid = true
+				break
+			}
+		}
+
+		if !valid {
+			*err = append(*err, parseError(ComponentTags, text, match[0], "unusual tag found for %s: %s", name, value))
+		}
 	}
 
-	return output, err
+	return value
 }
@@ -0,0 +1,96 @@
+package awips
+
+import "testing"
+
+func TestTagsParse(t *testing.T) {
+	tags, err := ParseTags(`
+LAT...LON 3581 7875 3573 7903 3589 7912 3600 7888
+TIME...MOT...LOC 2336Z 254DEG 21KT 3583 7896
+
+TORNADO...RADAR INDICATED
+TORNADO DAMAGE THREAT...CONSIDERABLE
+MAX HAIL SIZE...1.75 IN
+MAX WIND GUST...60 MPH
+`)
+	if len(err) > 0 {
+		for _, e := range err {
+			t.Errorf("failed to parse tags: %v", e)
+		}
+	}
+
+	if tags.Tornado != DetectionRadarIndicated {
+		t.Errorf("expected tornado '%s', got '%s'", DetectionRadarIndicated, tags.Tornado)
+	}
+	if tags.Damage != DamageConsiderable {
+		t.Errorf("expected damage '%s', got '%s'", DamageConsiderable, tags.Damage)
+	}
+	if tags.Hail == nil || tags.Hail.Size != 1.75 {
+		t.Errorf("expected hail size 1.75, got %v", tags.Hail)
+	}
+	if tags.Wind == nil || tags.Wind.Speed != 60 || tags.Wind.Unit != "MPH" {
+		t.Errorf("expected wind 60 MPH, got %v", tags.Wind)
+	}
+	if tags.HailThreat != "" {
+		t.Errorf("expected no hail threat, got '%s'", tags.HailThreat)
+	}
+}
+
+func TestTagsParseMarine(t *testing.T) {
+	tags, err := ParseTags(`
+WATERSPOUT...POSSIBLE
+WIND...>34KTS
+HAIL...<.75IN
+`)
+	if len(err) > 0 {
+		for _, e := range err {
+			t.Errorf("failed to parse tags: %v", e)
+		}
+	}
+
+	if tags.Spout != DetectionPossible {
+		t.Errorf("expected spout '%s', got '%s'", DetectionPossible, tags.Spout)
+	}
+	if tags.Wind == nil || tags.Wind.Speed != 34 || tags.Wind.Unit != "KTS" || tags.Wind.Comparison != ">" {
+		t.Errorf("expected wind >34 KTS, got %v", tags.Wind)
+	}
+	if tags.Hail == nil || tags.Hail.Size != 0.75 || tags.Hail.Comparison != "<" {
+		t.Errorf("expected hail <0.75, got %v", tags.Hail)
+	}
+}
+
+func TestTagsParseMixedCase(t *testing.T) {
+	tags, err := ParseTags(`
+Hail Threat...Radar Indicated
+Max Hail Size...1.00 in
+Wind Threat...Observed
+Max Wind Gust...70 mph
+`)
+	if len(err) > 0 {
+		for _, e := range err {
+			t.Errorf("failed to parse tags: %v", e)
+		}
+	}
+
+	if tags.HailThreat != DetectionRadarIndicated {
+		t.Errorf("expected hail threat '%s', got '%s'", DetectionRadarIndicated, tags.HailThreat)
+	}
+	if tags.WindThreat != DetectionObserved {
+		t.Errorf("expected wind threat '%s', got '%s'", DetectionObserved, tags.WindThreat)
+	}
+	if tags.Hail == nil || tags.Hail.Size != 1 {
+		t.Errorf("expected hail size 1.00, got %v", tags.Hail)
+	}
+	if tags.Wind == nil || tags.Wind.Speed != 70 || tags.Wind.Unit != "MPH" {
+		t.Errorf("expected wind 70 MPH, got %v", tags.Wind)
+	}
+}
+
+func TestTagsParseUnusual(t *testing.T) {
+	tags, err := ParseTags("TORNADO...LIKELY\n")
+	if len(err) != 1 {
+		t.Errorf("expected 1 error, got %d", len(err))
+	}
+	if tags.Tornado != "LIKELY" {
+		t.Errorf("expected unusual tornado tag to be kept, got '%s'", tags.Tornado)
+	}
+}
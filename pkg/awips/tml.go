@@ -1,7 +1,6 @@
 package awips
 
 import (
-	"errors"
 	"regexp"
 	"strconv"
 	"strings"
@@ -19,26 +18,32 @@ type TML struct {
 
 func ParseTML(text string, issued time.Time) (*TML, error) {
 	tmlRegexp := regexp.MustCompile(`(?m:^(TIME\.\.\.MOT\.\.\.LOC)([A-Za-z0-9 ]*))`)
-	original := strings.TrimSpace(tmlRegexp.FindString(text))
-
-	if original == "" {
+	index := tmlRegexp.FindStringIndex(text)
+	if index == nil {
 		return nil, nil
 	}
+	original := strings.TrimSpace(text[index[0]:index[1]])
 
-	segments := strings.Split(original, " ")[1:]
+	segments := strings.Fields(original)[1:]
+	if len(segments) < 3 {
+		return nil, parseError(ComponentTML, text, index[0], "expected time, motion and location in TML")
+	}
 
 	parsedTime, err := time.Parse(("1504Z"), segments[0])
 
 	if err != nil {
-		return nil, errors.New("could not parse TML time")
+		return nil, parseError(ComponentTML, text, index[0], "could not parse TML time")
 	}
 
 	time := time.Date(issued.Year(), issued.Month(), issued.Day(), parsedTime.Hour(), parsedTime.Minute(), 0, 0, time.Now().UTC().Location())
 
+	if len(segments[1]) < 3 {
+		return nil, parseError(ComponentTML, text, index[0], "could not parse direction in TML")
+	}
 	direction, err := strconv.Atoi(segments[1][:3])
 
 	if err != nil {
-		return nil, errors.New("could not parse direction in TML")
+		return nil, parseError(ComponentTML, text, index[0], "could not parse direction in TML")
 	}
 
 	numberRegexp := regexp.MustCompile("[0-9]+")
@@ -46,7 +51,7 @@ func ParseTML(text string, issued time.Time) (*TML, error) {
 	speed, err := strconv.Atoi(numberRegexp.FindString(speedString))
 
 	if err != nil {
-		return nil, errors.New("could not parse speed in TML")
+		return nil, parseError(ComponentTML, text, index[0], "could not parse speed in TML")
 	}
 
 	tml := TML{
@@ -61,11 +66,11 @@ func ParseTML(text string, issued time.Time) (*TML, error) {
 	for i := 3; i < len(segments)-1; i += 2 {
 		latString, err := strconv.Atoi(segments[i])
 		if err != nil {
-			return nil, errors.New("failed to parse LAT...LON lat")
+			return nil, parseError(ComponentTML, text, index[0], "failed to parse TML latitude")
 		}
 		lonString, err := strconv.Atoi(segments[i+1])
 		if err != nil {
-			return nil, errors.New("failed to parse LAT...LON lon")
+			return nil, parseError(ComponentTML, text, index[0], "failed to parse TML longitude")
 		}
 
 		lat := float64(latString) / 100
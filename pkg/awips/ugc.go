@@ -1,7 +1,6 @@
 package awips
 
 import (
-	"errors"
 	"fmt"
 	"regexp"
 	"strconv"
@@ -21,6 +20,12 @@ type State struct {
 	Areas []string `json:"areas"`
 }
 
+var (
+	ugcStateRegexp = regexp.MustCompile(`^([A-Z]{2})([CZ])(.*)$`)
+	ugcRangeRegexp = regexp.MustCompile(`^([0-9]{3})>([0-9]{3})$`)
+	ugcAreaRegexp  = regexp.MustCompile(`^[A-Z0-9]{3}$`)
+)
+
 func ParseUGC(text string) (*UGC, error) {
 	// Find the start of the UGC
 	ugcStartRegex := regexp.MustCompile("(?m:^[A-Z]{2}(C|Z)[A-Z0-9]{3}(-|>))")
@@ -53,48 +58,48 @@ func ParseUGC(text string) (*UGC, error) {
 	} else {
 		expires, err = time.Parse("021504", expiryString)
 		if err != nil {
-			return nil, errors.New("could not parse UGC expiry: " + err.Error())
+			return nil, parseError(ComponentUGC, text, startIndex[0], "could not parse UGC expiry: %s", err.Error())
 		}
 	}
 	segments = segments[:len(segments)-1]
 
 	// Group everything into states since that is the order of the UGC
 	states := []State{}
-	currentState := -1
-	alphabetRegexp := regexp.MustCompile("[A-Z]")
-	// UGC uses > to specify a range of zones/counties
-	bracketRegexp := regexp.MustCompile(">")
 
 	for _, s := range segments {
-		// If the
-		if alphabetRegexp.MatchString(s) {
-			currentState++
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+
+		// A new state starts with its ID and type, e.g. NCC063
+		if match := ugcStateRegexp.FindStringSubmatch(s); match != nil {
 			states = append(states, State{
-				ID:    s[0:2],
-				Type:  s[2:3],
+				ID:    match[1],
+				Type:  match[2],
 				Areas: []string{},
 			})
-			s = s[3:]
+			s = match[3]
 		}
 
-		// Get the range of the zones/counties
-		if bracketRegexp.MatchString(s) {
-			start, err := strconv.Atoi(s[:3])
-			if err != nil {
-				return nil, errors.New("could not parse UGC int: " + err.Error())
-			}
+		if len(states) == 0 {
+			return nil, parseError(ComponentUGC, text, startIndex[0], "UGC area %s has no state", s)
+		}
+		state := &states[len(states)-1]
 
-			end, err := strconv.Atoi(s[4:])
-			if err != nil {
-				return nil, errors.New("could not parse UGC int: " + err.Error())
-			}
+		// UGC uses > to specify a range of zones/counties
+		if match := ugcRangeRegexp.FindStringSubmatch(s); match != nil {
+			start, _ := strconv.Atoi(match[1])
+			end, _ := strconv.Atoi(match[2])
 
 			for i := start; i <= end; i++ {
 				// Format the ugc to be at least three digits padded with zeros
-				states[currentState].Areas = append(states[currentState].Areas, fmt.Sprintf("%03d", i))
+				state.Areas = append(state.Areas, fmt.Sprintf("%03d", i))
 			}
+		} else if ugcAreaRegexp.MatchString(s) {
+			state.Areas = append(state.Areas, s)
 		} else {
-			states[currentState].Areas = append(states[currentState].Areas, s)
+			return nil, parseError(ComponentUGC, text, startIndex[0], "invalid UGC area %s", s)
 		}
 	}
 
@@ -108,3 +113,14 @@ func ParseUGC(text string) (*UGC, error) {
 func (ugc *UGC) Merge(t time.Time) {
 	ugc.Expires = time.Date(t.Year(), t.Month(), ugc.Expires.Day(), ugc.Expires.Hour(), ugc.Expires.Minute(), t.Second(), t.Nanosecond(), time.UTC)
 }
+
+// Expands the UGC into the individual county/zone codes, e.g. NCC063
+func (ugc *UGC) Codes() []string {
+	codes := []string{}
+	for _, state := range ugc.States {
+		for _, area := range state.Areas {
+			codes = append(codes, state.ID+state.Type+area)
+		}
+	}
+	return codes
+}
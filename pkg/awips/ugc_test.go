@@ -0,0 +1,21 @@
+package awips
+
+import "testing"
+
+func TestUGCCodes(t *testing.T) {
+	ugc, err := ParseUGC("NCC063-135-145>147-VAC083-262345-\n")
+	if err != nil {
+		t.Fatalf("failed to parse UGC: %v", err)
+	}
+
+	expected := []string{"NCC063", "NCC135", "NCC145", "NCC146", "NCC147", "VAC083"}
+	codes := ugc.Codes()
+	if len(codes) != len(expected) {
+		t.Fatalf("expected %d codes, got %d: %v", len(expected), len(codes), codes)
+	}
+	for i, code := range expected {
+		if codes[i] != code {
+			t.Errorf("expected code %d to be '%s', got '%s'", i, code, codes[i])
+		}
+	}
+}
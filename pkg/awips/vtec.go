@@ -1,7 +1,6 @@
 package awips
 
 import (
-	"fmt"
 	"regexp"
 	"strconv"
 	"strings"
@@ -128,33 +127,34 @@ const VTECRegexp = `([A-Z])\.([A-Z]+)\.([A-Z]+)\.([A-Z]+)\.([A-Z])\.([0-9]+)\.([
 func ParseVTEC(text string) ([]VTEC, []error) {
 	// Find the VTECs
 	vtecRegex := regexp.MustCompile(VTECRegexp)
-	instances := vtecRegex.FindAllString(text, -1)
+	instances := vtecRegex.FindAllStringIndex(text, -1)
 
 	// There could be more than one
 	var vtecs []VTEC
 	// We will return an array of errors for debugging individual VTECs instead of failing a whole product parse
 	var err []error
 
-	for _, original := range instances {
+	for _, index := range instances {
+		original := text[index[0]:index[1]]
 
 		segments := strings.Split(original, ".")
 
-		if len(segments) < 6 {
-			err = append(err, fmt.Errorf("length of segments is %d, expected 6 for %s", len(segments), original))
+		if len(segments) != 7 {
+			err = append(err, parseError(ComponentVTEC, text, index[0], "length of segments is %d, expected 7 for %s", len(segments), original))
 			continue
 		}
 
 		// Get VTEC class
 		class := segments[0]
 		if _, ok := VTECClass[class]; !ok {
-			err = append(err, fmt.Errorf("invalid class %s for %s", class, original))
+			err = append(err, parseError(ComponentVTEC, text, index[0], "invalid class %s for %s", class, original))
 			continue
 		}
 
 		// Get VTEC action
 		action := segments[1]
 		if _, ok := VTECAction[action]; !ok {
-			err = append(err, fmt.Errorf("invalid action %s for %s", action, original))
+			err = append(err, parseError(ComponentVTEC, text, index[0], "invalid action %s for %s", action, original))
 			continue
 		}
 
@@ -164,14 +164,14 @@ func ParseVTEC(text string) ([]VTEC, []error) {
 		// Get phenomena
 		phenomena := segments[3]
 		if _, ok := VTECPhenomena[phenomena]; !ok {
-			err = append(err, fmt.Errorf("invalid phenomena %s for %s", phenomena, original))
+			err = append(err, parseError(ComponentVTEC, text, index[0], "invalid phenomena %s for %s", phenomena, original))
 			continue
 		}
 
 		// Get significance
 		significance := segments[4]
 		if _, ok := VTECSignificance[significance]; !ok {
-			err = append(err, fmt.Errorf("invalid significance %s for %s", significance, original))
+			err = append(err, parseError(ComponentVTEC, text, index[0], "invalid significance %s for %s", significance, original))
 			continue
 		}
 
@@ -179,13 +179,17 @@ func ParseVTEC(text string) ([]VTEC, []error) {
 		etnString := segments[5]
 		etn, e := strconv.Atoi(etnString)
 		if e != nil {
-			err = append(err, fmt.Errorf("invalid etn %s for %s", etnString, original))
+			err = append(err, parseError(ComponentVTEC, text, index[0], "invalid etn %s for %s", etnString, original))
 			continue
 		}
 
 		// Get time
 		datetimeString := segments[6]
 		dateSegments := strings.Split(datetimeString, "-")
+		if len(dateSegments) != 2 {
+			err = append(err, parseError(ComponentVTEC, text, index[0], "invalid time range %s for %s", datetimeString, original))
+			continue
+		}
 
 		layout := "060102T1504Z"
 
@@ -198,7 +202,7 @@ func ParseVTEC(text string) ([]VTEC, []error) {
 		if !zeroRegexp.MatchString(dateSegments[0]) {
 			t, e := time.Parse(layout, dateSegments[0])
 			if e != nil {
-				err = append(err, fmt.Errorf("failed to parse start time %s for %s", dateSegments[0], original))
+				err = append(err, parseError(ComponentVTEC, text, index[0], "failed to parse start time %s for %s", dateSegments[0], original))
 				continue
 			}
 
@@ -208,7 +212,7 @@ func ParseVTEC(text string) ([]VTEC, []error) {
 		if !zeroRegexp.MatchString(dateSegments[1]) {
 			t, e := time.Parse(layout, dateSegments[1])
 			if e != nil {
-				err = append(err, fmt.Errorf("failed to parse start time %s for %s", dateSegments[1], original))
+				err = append(err, parseError(ComponentVTEC, text, index[0], "failed to parse end time %s for %s", dateSegments[1], original))
 				continue
 			}
 
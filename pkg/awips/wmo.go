@@ -20,34 +20,33 @@ const WMORegexp = `([A-Z]{4}[0-9]{2})\s([A-Z]{4})\s([0-9]{6})( [A-Z]{3})?`
 func ParseWMO(text string) (WMO, error) {
 	// Find the WMO line
 	wmoRegexp := regexp.MustCompile(WMORegexp)
-	original := wmoRegexp.FindString(text)
-	if original == "" {
-		return WMO{}, errors.New("could not find WMO line")
+	match := wmoRegexp.FindStringSubmatchIndex(text)
+	if match == nil {
+		return WMO{}, NewParseError(ComponentWMO, text, -1, errors.New("could not find WMO line"))
+	}
+	original := text[match[0]:match[1]]
+	group := func(i int) string {
+		if match[2*i] < 0 {
+			return ""
+		}
+		return text[match[2*i]:match[2*i+1]]
 	}
-	// Segment the line
-	segments := strings.Split(original, " ")
 
 	// Time layout (ddhhmm)
 	layout := "021504"
 
 	// Issued day & time
-	t, err := time.Parse(layout, segments[2])
+	t, err := time.Parse(layout, group(3))
 	if err != nil {
-		return WMO{}, errors.New("could not find WMO issued datetime")
-	}
-
-	// bbb if any exists
-	bbb := ""
-	if len(segments) > 3 {
-		bbb = segments[3]
+		return WMO{}, parseError(ComponentWMO, text, match[0], "could not parse WMO issued datetime %s", group(3))
 	}
 
 	return WMO{
 		Original: original,
-		Datatype: segments[0],
-		Office:   segments[1],
+		Datatype: group(1),
+		Office:   group(2),
 		Issued:   t,
-		BBB:      bbb,
+		BBB:      strings.TrimSpace(group(4)),
 	}, nil
 }
 
@@ -55,7 +54,7 @@ func HasWMO(text string) (bool, error) {
 	wmoRegexp := regexp.MustCompile(WMORegexp)
 	original := wmoRegexp.FindString(text)
 	if original == "" {
-		return false, errors.New("could not find WMO line")
+		return false, NewParseError(ComponentWMO, text, -1, errors.New("could not find WMO line"))
 	}
 	return true, nil
 }
@@ -0,0 +1,17 @@
+package db
+
+import "time"
+
+// Product processing log
+type Log struct {
+	ID        int        `json:"id"`
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+	Time      *time.Time `json:"time"`
+	Level     string     `json:"level"`
+	Product   string     `json:"product"`
+	AWIPS     string     `json:"awips"`
+	WMO       string     `json:"wmo"`
+	Text      string     `json:"text"`
+	Message   string     `json:"message"`
+	Failed    bool       `json:"failed"` // The reason the product could not be stored
+}
@@ -56,9 +56,10 @@ type VTECUpdate struct {
 	Tornado       string
 	Damage        string
 	HailThreat    string
-	HailTag       string
+	HailTag       *float64 // Inches
 	WindThreat    string
-	WindTag       string
+	WindTag       *int
+	WindTagUnit   string // MPH or KTS
 	FlashFlood    string
 	RainfallTag   string
 	FloodTagDam   string
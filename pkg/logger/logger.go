@@ -2,26 +2,38 @@ package logger
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
 	"os"
+	"slices"
 	"time"
 
-	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/pgconn"
 )
 
 type LogRecord struct {
-	Time  time.Time  `json:"time"`
-	Level slog.Level `json:"level"`
-	Msg   string     `json:"msg"`
+	Time   time.Time  `json:"time"`
+	Level  slog.Level `json:"level"`
+	Msg    string     `json:"msg"`
+	Failed bool       `json:"failed"` // Why the product could not be stored
 }
 
 type Logger struct {
 	logger  *slog.Logger
-	db      *pgxpool.Pool
 	Records []LogRecord `json:"-"`
+	errors  []string    // Kept after the records are committed
+	Product string      // The stored product's ID
+	AWIPS   string      // The AWIPS header
+	WMO     string      // The WMO header
+	Text    string      // The product text
 }
 
-func New(db *pgxpool.Pool, level slog.Level) Logger {
+// The database, or transaction, that logs are committed to
+type DB interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+}
+
+func New(level slog.Level) Logger {
 
 	opts := &slog.HandlerOptions{
 		Level: level,
@@ -29,7 +41,6 @@ func New(db *pgxpool.Pool, level slog.Level) Logger {
 
 	logger := Logger{
 		logger: slog.New(slog.NewTextHandler(os.Stdout, opts)),
-		db:     db,
 	}
 
 	return logger
@@ -53,41 +64,66 @@ func (logger *Logger) Info(msg string) {
 }
 
 func (logger *Logger) Warn(msg string, args ...any) {
-	logger.addRecord(msg, slog.LevelWarn)
+	logger.addRecord(formatArgs(msg, args), slog.LevelWarn)
 
 	logger.logger.Warn(msg, args...)
 }
 
 func (logger *Logger) Error(msg string, args ...any) {
-	logger.addRecord(msg, slog.LevelError)
+	formatted := formatArgs(msg, args)
+	logger.addRecord(formatted, slog.LevelError)
+	logger.errors = append(logger.errors, formatted)
 
 	logger.logger.Error(msg, args...)
 }
 
-// TODO
-func (logger *Logger) Commit() error {
+// Logs an error that stopped the product from being stored
+func (logger *Logger) Fail(msg string, args ...any) {
+	logger.Error(msg, args...)
+	logger.Records[len(logger.Records)-1].Failed = true
+}
 
-	// logs := []db.Log{}
+// Stores the records that meet the minimum level against the product. The text is only stored once, with the failure
+// if there is one, otherwise with the first error or the first record.
+func (logger *Logger) Commit(ctx context.Context, db DB) error {
+	records := []LogRecord{}
+	for _, record := range logger.Records {
+		if logger.logger.Enabled(ctx, record.Level) {
+			records = append(records, record)
+		}
+	}
 
-	// for _, record := range logger.Records {
-	// 	if !logger.logger.Enabled(context.TODO(), record.Level) {
-	// 		continue
-	// 	}
+	withText := slices.IndexFunc(records, func(record LogRecord) bool { return record.Failed })
+	if withText == -1 {
+		withText = slices.IndexFunc(records, func(record LogRecord) bool { return record.Level == slog.LevelError })
+	}
+	if withText == -1 && len(records) > 0 {
+		withText = 0
+	}
 
-	// 	logs = append(logs, db.Log{
-	// 		Time:    &record.Time,
-	// 		Level:   record.Level.String(),
-	// 		Product: logger.Product,
-	// 		AWIPS:   logger.AWIPS,
-	// 		WMO:     logger.WMO,
-	// 		Text:    logger.Text,
-	// 		Message: record.Msg,
-	// 	})
-	// }
+	for i, record := range records {
+		text := ""
+		if i == withText {
+			text = logger.Text
+		}
+
+		_, err := db.Exec(ctx, `
+		INSERT INTO awips.logs (time, level, product, awips, wmo, text, message, failed) VALUES
+		($1, $2, NULLIF($3, ''), $4, $5, NULLIF($6, ''), $7, $8);
+		`, record.Time, record.Level.String(), logger.Product, logger.AWIPS, logger.WMO, text, record.Msg, record.Failed)
+		if err != nil {
+			return err
+		}
+	}
 
 	return nil
 }
 
+// The error messages that have been logged
+func (logger *Logger) Errors() []string {
+	return logger.errors
+}
+
 func (logger *Logger) With(args ...any) {
 	logger.logger = logger.logger.With(args...)
 }
@@ -99,3 +135,15 @@ func (logger *Logger) addRecord(msg string, level slog.Level) {
 		Msg:   msg,
 	})
 }
+
+// Append the key value pairs of the arguments to the message as slog would, e.g. failed to parse MCD error="..."
+func formatArgs(msg string, args []any) string {
+	for i := 0; i < len(args); i += 2 {
+		if i+1 == len(args) {
+			msg += fmt.Sprintf(" !BADKEY=%v", args[i])
+			break
+		}
+		msg += fmt.Sprintf(" %v=%q", args[i], fmt.Sprint(args[i+1]))
+	}
+	return msg
+}